@@ -0,0 +1,105 @@
+// display is an internal package
+// It provides formatting for the information shown about a Pokemon,
+// kept apart from the commands so that it can be tested
+package display
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	pokeapi "github.com/nicholasss/pokedexcli/internal/pokeapi"
+)
+
+// =========
+// Constants
+// =========
+
+// The system of measurement used for height and weight.
+type Units string
+
+const (
+	Metric   Units = "metric"
+	Imperial Units = "imperial"
+)
+
+// the number of characters in a full stat bar
+const statBarWidth = 20
+
+// the width of the label column, so that values line up
+const labelWidth = 16
+
+// =====
+// Types
+// =====
+
+// Options changes what is shown by Inspect.
+type Options struct {
+	Units Units
+	// the name shown instead of the API name, such as a localized name
+	DisplayName string
+	// a description of the species, left out when empty
+	Description string
+}
+
+// ====================
+// Formatting Functions
+// ====================
+
+// takes a height in decimetres, as given by the API
+func FormatHeight(decimetres int, units Units) string {
+	if units == Imperial {
+		totalInches := int(float64(decimetres)*3.937 + 0.5)
+		return fmt.Sprintf("%d'%02d\"", totalInches/12, totalInches%12)
+	}
+
+	return fmt.Sprintf("%.1f m", float64(decimetres)/10)
+}
+
+// takes a weight in hectograms, as given by the API
+func FormatWeight(hectograms int, units Units) string {
+	if units == Imperial {
+		return fmt.Sprintf("%.1f lbs", float64(hectograms)*0.220462)
+	}
+
+	return fmt.Sprintf("%.1f kg", float64(hectograms)/10)
+}
+
+// draws a bar for a base stat, scaled so that a full bar is the highest possible stat
+func StatBar(value int) string {
+	value = min(max(value, 0), pokeapi.MaxBaseStat)
+	filled := (value*statBarWidth + pokeapi.MaxBaseStat/2) / pokeapi.MaxBaseStat
+	return strings.Repeat("█", filled) + strings.Repeat("░", statBarWidth-filled)
+}
+
+// writes the details of a pokemon in an aligned layout
+func Inspect(w io.Writer, pokemon pokeapi.PokemonInfo, opts Options) error {
+	name := opts.DisplayName
+	if name == "" {
+		name = pokemon.Name
+	}
+
+	var types []string
+	for _, pType := range pokemon.TypeList {
+		types = append(types, pType.PType.Name)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-*s%s\n", labelWidth, "Name:", name)
+	fmt.Fprintf(&b, "%-*s%s\n", labelWidth, "Height:", FormatHeight(pokemon.Height, opts.Units))
+	fmt.Fprintf(&b, "%-*s%s\n", labelWidth, "Weight:", FormatWeight(pokemon.Weight, opts.Units))
+	fmt.Fprintf(&b, "%-*s%s\n", labelWidth, "Types:", strings.Join(types, ", "))
+
+	b.WriteString("Stats:\n")
+	for _, stat := range pokemon.StatList {
+		fmt.Fprintf(&b, "  %-*s%3d  %s\n", labelWidth-2, stat.Stat.Name, stat.BaseStat, StatBar(stat.BaseStat))
+	}
+	fmt.Fprintf(&b, "  %-*s%3d\n", labelWidth-2, "total", pokeapi.BaseStatTotal(pokemon))
+
+	if opts.Description != "" {
+		fmt.Fprintf(&b, "Description:\n  %s\n", opts.Description)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
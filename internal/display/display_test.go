@@ -0,0 +1,111 @@
+package display_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	display "github.com/nicholasss/pokedexcli/internal/display"
+	pokeapi "github.com/nicholasss/pokedexcli/internal/pokeapi"
+)
+
+func TestFormatUnits(t *testing.T) {
+	cases := []struct {
+		height         int
+		weight         int
+		units          display.Units
+		expectedHeight string
+		expectedWeight string
+	}{
+		{height: 7, weight: 69, units: display.Metric, expectedHeight: "0.7 m", expectedWeight: "6.9 kg"},
+		{height: 7, weight: 69, units: display.Imperial, expectedHeight: "2'04\"", expectedWeight: "15.2 lbs"},
+		{height: 20, weight: 4600, units: display.Metric, expectedHeight: "2.0 m", expectedWeight: "460.0 kg"},
+		{height: 20, weight: 4600, units: display.Imperial, expectedHeight: "6'07\"", expectedWeight: "1014.1 lbs"},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			height := display.FormatHeight(c.height, c.units)
+			if height != c.expectedHeight {
+				t.Errorf("expected height '%s', got '%s'", c.expectedHeight, height)
+			}
+
+			weight := display.FormatWeight(c.weight, c.units)
+			if weight != c.expectedWeight {
+				t.Errorf("expected weight '%s', got '%s'", c.expectedWeight, weight)
+			}
+		})
+	}
+}
+
+func TestStatBar(t *testing.T) {
+	cases := []struct {
+		value  int
+		filled int
+	}{
+		{value: 0, filled: 0},
+		{value: 255, filled: 20},
+		{value: 300, filled: 20},
+		{value: 128, filled: 10},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			bar := display.StatBar(c.value)
+			if strings.Count(bar, "█") != c.filled {
+				t.Errorf("expected %d filled for %d, got '%s'", c.filled, c.value, bar)
+			}
+			if len([]rune(bar)) != 20 {
+				t.Errorf("expected a bar of 20 characters, got '%s'", bar)
+			}
+		})
+	}
+}
+
+func TestInspect(t *testing.T) {
+	data := []byte(`{
+		"name": "pikachu",
+		"height": 4,
+		"weight": 60,
+		"stats": [
+			{"base_stat": 35, "stat": {"name": "hp"}},
+			{"base_stat": 90, "stat": {"name": "speed"}}
+		],
+		"types": [{"type": {"name": "electric"}}]
+	}`)
+
+	var pokemon pokeapi.PokemonInfo
+	if err := json.Unmarshal(data, &pokemon); err != nil {
+		t.Errorf("unable to unmarshal pokemon: %s", err)
+		return
+	}
+
+	expected := "" +
+		"Name:           Pikachu\n" +
+		"Height:         0.4 m\n" +
+		"Weight:         6.0 kg\n" +
+		"Types:          electric\n" +
+		"Stats:\n" +
+		"  hp             35  " + display.StatBar(35) + "\n" +
+		"  speed          90  " + display.StatBar(90) + "\n" +
+		"  total         125\n" +
+		"Description:\n" +
+		"  It keeps its tail raised.\n"
+
+	var actual bytes.Buffer
+	err := display.Inspect(&actual, pokemon, display.Options{
+		Units:       display.Metric,
+		DisplayName: "Pikachu",
+		Description: "It keeps its tail raised.",
+	})
+	if err != nil {
+		t.Errorf("unable to inspect: %s", err)
+		return
+	}
+
+	if actual.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual.String())
+	}
+}
@@ -0,0 +1,264 @@
+// export is an internal package
+// It provides writing the pokedex in file formats that can be shared,
+// each format is an Exporter so that more can be added
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	pokeapi "github.com/nicholasss/pokedexcli/internal/pokeapi"
+	pokedex "github.com/nicholasss/pokedexcli/internal/pokedex"
+)
+
+// =====
+// Types
+// =====
+
+// An Exporter writes rows in a single file format.
+type Exporter interface {
+	Export(w io.Writer, rows []Row) error
+}
+
+// A caught pokemon with only the fields that are exported.
+type Row struct {
+	ID       int       `json:"id"`
+	Name     string    `json:"name"`
+	Types    []string  `json:"types"`
+	Stats    []Stat    `json:"stats"`
+	CaughtAt time.Time `json:"caught_at"`
+}
+
+// A single base stat.
+type Stat struct {
+	Name string `json:"name"`
+	Base int    `json:"base"`
+}
+
+// the exporters by the name of their format
+var exporters = map[string]Exporter{
+	"csv":      CSV{},
+	"json":     JSON{},
+	"markdown": Markdown{},
+	"md":       Markdown{},
+	"yaml":     YAML{},
+	"yml":      YAML{},
+}
+
+// ==================
+// Exporter Functions
+// ==================
+
+// returns the exporter for the format
+func ForFormat(format string) (Exporter, error) {
+	exporter, ok := exporters[strings.ToLower(format)]
+	if !ok {
+		return nil, fmt.Errorf("unknown export format '%s', use one of: %s", format, strings.Join(Formats(), ", "))
+	}
+
+	return exporter, nil
+}
+
+// returns the name of every format, sorted
+func Formats() []string {
+	formats := make([]string, 0, len(exporters))
+	for format := range exporters {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+
+	return formats
+}
+
+// turns caught pokemon into rows using the data of their species,
+// sorted by pokedex number
+func Rows(species []pokeapi.PokemonInfo, caught []pokedex.CaughtPokemon) []Row {
+	bySpecies := make(map[string]pokeapi.PokemonInfo)
+	for _, pokemon := range species {
+		bySpecies[pokemon.Name] = pokemon
+	}
+
+	rows := make([]Row, 0, len(caught))
+	for _, individual := range caught {
+		pokemon := bySpecies[individual.Species]
+
+		row := Row{
+			ID:       pokemon.ID,
+			Name:     individual.Species,
+			Types:    []string{},
+			Stats:    []Stat{},
+			CaughtAt: individual.CaughtAt,
+		}
+		for _, pType := range pokemon.TypeList {
+			row.Types = append(row.Types, pType.PType.Name)
+		}
+		for _, stat := range pokemon.StatList {
+			row.Stats = append(row.Stats, Stat{Name: stat.Stat.Name, Base: stat.BaseStat})
+		}
+
+		rows = append(rows, row)
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].ID != rows[j].ID {
+			return rows[i].ID < rows[j].ID
+		}
+		return rows[i].Name < rows[j].Name
+	})
+
+	return rows
+}
+
+// returns the name of every stat in the order they first appear,
+// so that each stat gets its own column
+func statNames(rows []Row) []string {
+	var names []string
+	seen := make(map[string]bool)
+
+	for _, row := range rows {
+		for _, stat := range row.Stats {
+			if !seen[stat.Name] {
+				seen[stat.Name] = true
+				names = append(names, stat.Name)
+			}
+		}
+	}
+
+	return names
+}
+
+// returns the base stat by name, or nothing when the row does not have it
+func statValue(row Row, name string) string {
+	for _, stat := range row.Stats {
+		if stat.Name == name {
+			return strconv.Itoa(stat.Base)
+		}
+	}
+
+	return ""
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// =========
+// Exporters
+// =========
+
+// Writes a header row and a row for each pokemon, with a column for each stat.
+type CSV struct{}
+
+func (CSV) Export(w io.Writer, rows []Row) error {
+	stats := statNames(rows)
+
+	writer := csv.NewWriter(w)
+
+	header := append([]string{"id", "name", "types"}, stats...)
+	header = append(header, "caught_at")
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		record := []string{strconv.Itoa(row.ID), row.Name, strings.Join(row.Types, "/")}
+		for _, stat := range stats {
+			record = append(record, statValue(row, stat))
+		}
+		record = append(record, formatTime(row.CaughtAt))
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// Writes an indented list of pokemon.
+type JSON struct{}
+
+func (JSON) Export(w io.Writer, rows []Row) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(rows)
+}
+
+// Writes a table with a column for each stat and the base stat total.
+type Markdown struct{}
+
+func (Markdown) Export(w io.Writer, rows []Row) error {
+	stats := statNames(rows)
+
+	var b strings.Builder
+
+	header := append([]string{"ID", "Name", "Types"}, stats...)
+	header = append(header, "Total", "Caught")
+	b.WriteString("| " + strings.Join(header, " | ") + " |\n")
+	b.WriteString(strings.Repeat("| --- ", len(header)) + "|\n")
+
+	for _, row := range rows {
+		total := 0
+		for _, stat := range row.Stats {
+			total += stat.Base
+		}
+
+		cells := []string{strconv.Itoa(row.ID), row.Name, strings.Join(row.Types, ", ")}
+		for _, stat := range stats {
+			cells = append(cells, statValue(row, stat))
+		}
+		cells = append(cells, strconv.Itoa(total), formatTime(row.CaughtAt))
+
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Writes a list of pokemon, with strings quoted so that no name needs escaping.
+type YAML struct{}
+
+func (YAML) Export(w io.Writer, rows []Row) error {
+	var b strings.Builder
+
+	if len(rows) == 0 {
+		b.WriteString("[]\n")
+	}
+
+	for _, row := range rows {
+		fmt.Fprintf(&b, "- id: %d\n", row.ID)
+		fmt.Fprintf(&b, "  name: %s\n", strconv.Quote(row.Name))
+
+		if len(row.Types) == 0 {
+			b.WriteString("  types: []\n")
+		} else {
+			b.WriteString("  types:\n")
+		}
+		for _, pType := range row.Types {
+			fmt.Fprintf(&b, "    - %s\n", strconv.Quote(pType))
+		}
+
+		if len(row.Stats) == 0 {
+			b.WriteString("  stats: {}\n")
+		} else {
+			b.WriteString("  stats:\n")
+		}
+		for _, stat := range row.Stats {
+			fmt.Fprintf(&b, "    %s: %d\n", strconv.Quote(stat.Name), stat.Base)
+		}
+
+		fmt.Fprintf(&b, "  caught_at: %s\n", formatTime(row.CaughtAt))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
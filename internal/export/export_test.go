@@ -0,0 +1,127 @@
+package export_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	export "github.com/nicholasss/pokedexcli/internal/export"
+	pokeapi "github.com/nicholasss/pokedexcli/internal/pokeapi"
+	pokedex "github.com/nicholasss/pokedexcli/internal/pokedex"
+)
+
+// returns the species and caught pokemon for two pokemon, out of pokedex order
+func testRecords(t *testing.T) ([]pokeapi.PokemonInfo, []pokedex.CaughtPokemon) {
+	data := []string{
+		`{"id": 25, "name": "pikachu", "stats": [{"base_stat": 35, "stat": {"name": "hp"}}, {"base_stat": 90, "stat": {"name": "speed"}}], "types": [{"type": {"name": "electric"}}]}`,
+		`{"id": 1, "name": "bulbasaur", "stats": [{"base_stat": 45, "stat": {"name": "hp"}}, {"base_stat": 45, "stat": {"name": "speed"}}], "types": [{"type": {"name": "grass"}}, {"type": {"name": "poison"}}]}`,
+	}
+
+	var species []pokeapi.PokemonInfo
+	var caught []pokedex.CaughtPokemon
+	for i, pokemonData := range data {
+		var pokemon pokeapi.PokemonInfo
+		if err := json.Unmarshal([]byte(pokemonData), &pokemon); err != nil {
+			t.Fatalf("unable to unmarshal pokemon: %s", err)
+		}
+
+		species = append(species, pokemon)
+		caught = append(caught, pokedex.CaughtPokemon{
+			ID:       fmt.Sprintf("%06x", i),
+			Species:  pokemon.Name,
+			CaughtAt: time.Date(2025, 1, 1+i, 12, 0, 0, 0, time.UTC),
+		})
+	}
+
+	return species, caught
+}
+
+func TestExporters(t *testing.T) {
+	cases := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: "csv",
+			expected: "id,name,types,hp,speed,caught_at\n" +
+				"1,bulbasaur,grass/poison,45,45,2025-01-02T12:00:00Z\n" +
+				"25,pikachu,electric,35,90,2025-01-01T12:00:00Z\n",
+		},
+		{
+			format: "markdown",
+			expected: "| ID | Name | Types | hp | speed | Total | Caught |\n" +
+				"| --- | --- | --- | --- | --- | --- | --- |\n" +
+				"| 1 | bulbasaur | grass, poison | 45 | 45 | 90 | 2025-01-02T12:00:00Z |\n" +
+				"| 25 | pikachu | electric | 35 | 90 | 125 | 2025-01-01T12:00:00Z |\n",
+		},
+		{
+			format: "yaml",
+			expected: "- id: 1\n" +
+				"  name: \"bulbasaur\"\n" +
+				"  types:\n" +
+				"    - \"grass\"\n" +
+				"    - \"poison\"\n" +
+				"  stats:\n" +
+				"    \"hp\": 45\n" +
+				"    \"speed\": 45\n" +
+				"  caught_at: 2025-01-02T12:00:00Z\n" +
+				"- id: 25\n" +
+				"  name: \"pikachu\"\n" +
+				"  types:\n" +
+				"    - \"electric\"\n" +
+				"  stats:\n" +
+				"    \"hp\": 35\n" +
+				"    \"speed\": 90\n" +
+				"  caught_at: 2025-01-01T12:00:00Z\n",
+		},
+	}
+
+	rows := export.Rows(testRecords(t))
+
+	for _, c := range cases {
+		t.Run(c.format, func(t *testing.T) {
+			exporter, err := export.ForFormat(c.format)
+			if err != nil {
+				t.Errorf("unable to find exporter: %s", err)
+				return
+			}
+
+			var actual bytes.Buffer
+			if err := exporter.Export(&actual, rows); err != nil {
+				t.Errorf("unable to export: %s", err)
+				return
+			}
+
+			if actual.String() != c.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", c.expected, actual.String())
+			}
+		})
+	}
+}
+
+func TestJSONExporter(t *testing.T) {
+	rows := export.Rows(testRecords(t))
+
+	var actual bytes.Buffer
+	if err := (export.JSON{}).Export(&actual, rows); err != nil {
+		t.Errorf("unable to export: %s", err)
+		return
+	}
+
+	// the output is read back to check it is the same rows
+	var decoded []export.Row
+	if err := json.Unmarshal(actual.Bytes(), &decoded); err != nil {
+		t.Errorf("unable to read exported json: %s", err)
+		return
+	}
+
+	if len(decoded) != 2 || decoded[0].Name != "bulbasaur" || decoded[1].Stats[1].Base != 90 {
+		t.Errorf("expected exported rows to match, got %+v", decoded)
+	}
+
+	if _, err := export.ForFormat("pdf"); err == nil {
+		t.Errorf("expected an unknown format to be rejected")
+	}
+}
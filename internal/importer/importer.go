@@ -0,0 +1,161 @@
+// importer is an internal package
+// It provides reading lists of Pokemon to import, from CSV files,
+// lists of names or IDs, and ranges of Pokedex numbers such as 1-151
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// a range larger than every Pokemon is most likely a typo
+const maxRange = 2000
+
+// a range of Pokedex numbers, such as 1-151
+var numberRange = regexp.MustCompile(`^(\d+)\s*-\s*(\d+)$`)
+
+// A single Pokemon to import, as written in the file.
+type Entry struct {
+	// the line of the file the entry was on, starting at 1
+	Line int
+	// a name or Pokedex number
+	Value string
+}
+
+// A line of the file that could not be read as an entry.
+type Problem struct {
+	Line   int
+	Value  string
+	Reason string
+}
+
+// reads every entry in the file.
+// a file whose first line has commas is read as CSV, using the "name" or "id"
+// column when there is a header, otherwise every line is a name, number or range.
+// empty lines and lines starting with # are skipped
+func Parse(r io.Reader) ([]Entry, []Problem, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	var values []Entry
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		values = append(values, Entry{Line: i + 1, Value: line})
+	}
+
+	if len(values) > 0 && strings.Contains(values[0].Value, ",") {
+		var err error
+		values, err = csvValues(values)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var entries []Entry
+	var problems []Problem
+	for _, value := range values {
+		expanded, problem := expandRange(value)
+		if problem != nil {
+			problems = append(problems, *problem)
+			continue
+		}
+		entries = append(entries, expanded...)
+	}
+
+	return entries, problems, nil
+}
+
+// picks the name or id column from each row
+func csvValues(lines []Entry) ([]Entry, error) {
+	column := 0
+	first := 0
+
+	header, err := parseRow(lines[0].Value)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read line %d: %w", lines[0].Line, err)
+	}
+
+	// a name is preferred over an id, as it can be read by people too
+	for _, wanted := range []string{"name", "id"} {
+		found := false
+		for i, field := range header {
+			if strings.EqualFold(strings.TrimSpace(field), wanted) {
+				column = i
+				first = 1
+				found = true
+				break
+			}
+		}
+		if found {
+			break
+		}
+	}
+
+	var values []Entry
+	for _, line := range lines[first:] {
+		row, err := parseRow(line.Value)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read line %d: %w", line.Line, err)
+		}
+
+		value := ""
+		if column < len(row) {
+			value = strings.TrimSpace(row[column])
+		}
+		values = append(values, Entry{Line: line.Line, Value: value})
+	}
+
+	return values, nil
+}
+
+func parseRow(line string) ([]string, error) {
+	reader := csv.NewReader(strings.NewReader(line))
+	reader.FieldsPerRecord = -1
+
+	return reader.Read()
+}
+
+// turns a range of numbers into an entry for each number,
+// any other value is returned as it is
+func expandRange(entry Entry) ([]Entry, *Problem) {
+	if entry.Value == "" {
+		return nil, &Problem{Line: entry.Line, Value: entry.Value, Reason: "empty entry"}
+	}
+
+	match := numberRange.FindStringSubmatch(entry.Value)
+	if match == nil {
+		return []Entry{entry}, nil
+	}
+
+	start, startErr := strconv.Atoi(match[1])
+	end, endErr := strconv.Atoi(match[2])
+	switch {
+	case startErr != nil || endErr != nil:
+		return nil, &Problem{Line: entry.Line, Value: entry.Value, Reason: "invalid range"}
+	case start < 1 || start > end:
+		return nil, &Problem{Line: entry.Line, Value: entry.Value, Reason: "range must go from low to high, starting at 1"}
+	case end-start >= maxRange:
+		return nil, &Problem{Line: entry.Line, Value: entry.Value, Reason: "range is too large"}
+	}
+
+	entries := make([]Entry, 0, end-start+1)
+	for number := start; number <= end; number++ {
+		entries = append(entries, Entry{Line: entry.Line, Value: strconv.Itoa(number)})
+	}
+
+	return entries, nil
+}
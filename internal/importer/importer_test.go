@@ -0,0 +1,73 @@
+package importer_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	importer "github.com/nicholasss/pokedexcli/internal/importer"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		input            string
+		expected         []string
+		expectedProblems []int
+	}{
+		{
+			input:    "pikachu\n\n# starters\nbulbasaur\n 25 \n",
+			expected: []string{"pikachu", "bulbasaur", "25"},
+		},
+		{
+			input:    "1-3\nmew\n",
+			expected: []string{"1", "2", "3", "mew"},
+		},
+		{
+			input:            "5-1\n1-99999\nho-oh\n",
+			expected:         []string{"ho-oh"},
+			expectedProblems: []int{1, 2},
+		},
+		{
+			input:    "id,name,types\n1,bulbasaur,grass/poison\n25,pikachu,electric\n",
+			expected: []string{"bulbasaur", "pikachu"},
+		},
+		{
+			input:    "number,ID\n1,4\n2,7\n",
+			expected: []string{"4", "7"},
+		},
+		{
+			input:            "eevee,normal\nvaporeon,water\n,fire\n",
+			expected:         []string{"eevee", "vaporeon"},
+			expectedProblems: []int{3},
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			entries, problems, err := importer.Parse(strings.NewReader(c.input))
+			if err != nil {
+				t.Errorf("unable to parse: %s", err)
+				return
+			}
+
+			var actual []string
+			for _, entry := range entries {
+				actual = append(actual, entry.Value)
+			}
+
+			if strings.Join(actual, " ") != strings.Join(c.expected, " ") {
+				t.Errorf("expected entries %v, got %v", c.expected, actual)
+			}
+
+			if len(problems) != len(c.expectedProblems) {
+				t.Errorf("expected problems on lines %v, got %+v", c.expectedProblems, problems)
+				return
+			}
+			for j, problem := range problems {
+				if problem.Line != c.expectedProblems[j] {
+					t.Errorf("expected problems on lines %v, got %+v", c.expectedProblems, problems)
+				}
+			}
+		})
+	}
+}
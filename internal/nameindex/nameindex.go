@@ -0,0 +1,331 @@
+// nameindex is an internal package
+// It provides a local index of PokeAPI resource names, used to resolve
+// mistyped names and numeric IDs before any request is made
+package nameindex
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	pokeapi "github.com/nicholasss/pokedexcli/internal/pokeapi"
+)
+
+// =========
+// Constants
+// =========
+
+// the list endpoints will return every resource when given a large limit
+const listLimit = 100000
+
+// the most suggestions given for a single name
+const maxSuggestions = 5
+
+// A Kind is the name of a PokeAPI list endpoint.
+type Kind string
+
+const (
+	Pokemon        Kind = "pokemon"
+	LocationArea   Kind = "location-area"
+	Move           Kind = "move"
+	Item           Kind = "item"
+	Language       Kind = "language"
+	Ability        Kind = "ability"
+	Nature         Kind = "nature"
+	PokemonSpecies Kind = "pokemon-species"
+	Generation     Kind = "generation"
+	Pokedex        Kind = "pokedex"
+)
+
+// =====
+// Types
+// =====
+
+// A single named resource and its ID.
+type Entry struct {
+	Name string `json:"name"`
+	ID   int    `json:"id"`
+}
+
+// An Index holds every name for each kind that has been requested,
+// and is written to disk so that each list is only fetched once.
+// Aliases map other names, such as localized ones, back to the API name.
+type Index struct {
+	path    string
+	entries map[Kind][]Entry
+	aliases map[Kind]map[string]string
+	// aliases were added since the index was last written, see Flush
+	unsaved bool
+	mux     *sync.Mutex
+}
+
+// The layout of the index on disk.
+type indexFile struct {
+	Entries map[Kind][]Entry           `json:"entries"`
+	Aliases map[Kind]map[string]string `json:"aliases"`
+}
+
+// Returned by Resolve when the input does not match any name.
+type NotFoundError struct {
+	Kind        Kind
+	Input       string
+	Suggestions []string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("unable to find %s '%s'", e.Kind, e.Input)
+}
+
+// ===============
+// Index Functions
+// ===============
+
+// Initializes a new Index.
+// Any names that were previously saved at the path are loaded.
+func NewIndex(path string) *Index {
+	newIndex := Index{
+		path:    path,
+		entries: make(map[Kind][]Entry),
+		aliases: make(map[Kind]map[string]string),
+		mux:     &sync.Mutex{},
+	}
+
+	// a missing or unreadable file only means the lists get fetched again
+	var saved indexFile
+	data, err := os.ReadFile(path)
+	if err == nil && json.Unmarshal(data, &saved) == nil {
+		if saved.Entries != nil {
+			newIndex.entries = saved.Entries
+		}
+		if saved.Aliases != nil {
+			newIndex.aliases = saved.Aliases
+		}
+	}
+
+	return &newIndex
+}
+
+// returns every entry of the given kind,
+// fetching the list from the API the first time it is needed.
+// the lock is not held while fetching, so that other kinds can be resolved meanwhile
+func (idx *Index) Entries(kind Kind) ([]Entry, error) {
+	idx.mux.Lock()
+	entries, ok := idx.entries[kind]
+	idx.mux.Unlock()
+
+	if ok {
+		return entries, nil
+	}
+
+	URL := fmt.Sprintf("%s%s?offset=0&limit=%d", pokeapi.BaseURL, kind, listLimit)
+	data, err := pokeapi.RequestGETBody(URL)
+	if err != nil {
+		return []Entry{}, fmt.Errorf("unable to request %s list: %w", kind, err)
+	}
+
+	resourceList, err := pokeapi.UnmarshalResourceList(data)
+	if err != nil {
+		return []Entry{}, fmt.Errorf("unable to unmarshal %s list: %w", kind, err)
+	}
+
+	entries = make([]Entry, 0, len(resourceList.Results))
+	for _, result := range resourceList.Results {
+		ID, err := pokeapi.IDFromURL(result.URL)
+		if err != nil {
+			return []Entry{}, err
+		}
+		entries = append(entries, Entry{Name: result.Name, ID: ID})
+	}
+
+	idx.mux.Lock()
+	defer idx.mux.Unlock()
+
+	// the list may have been fetched at the same time, the first one stored is kept
+	if stored, ok := idx.entries[kind]; ok {
+		return stored, nil
+	}
+	idx.entries[kind] = entries
+
+	if err := idx.save(); err != nil {
+		return entries, fmt.Errorf("unable to save name index: %w", err)
+	}
+
+	return entries, nil
+}
+
+// records another name that should resolve to the given API name.
+// new aliases are kept in memory until Flush, so that naming a whole list writes the index once
+func (idx *Index) AddAlias(kind Kind, alias, name string) {
+	alias = strings.ToLower(strings.TrimSpace(alias))
+	if alias == "" || alias == name {
+		return
+	}
+
+	idx.mux.Lock()
+	defer idx.mux.Unlock()
+
+	if _, ok := idx.aliases[kind]; !ok {
+		idx.aliases[kind] = make(map[string]string)
+	}
+
+	if idx.aliases[kind][alias] == name {
+		return
+	}
+	idx.aliases[kind][alias] = name
+	idx.unsaved = true
+}
+
+// writes the index to disk when aliases were added since it was last written
+func (idx *Index) Flush() error {
+	idx.mux.Lock()
+	defer idx.mux.Unlock()
+
+	if !idx.unsaved {
+		return nil
+	}
+
+	return idx.save()
+}
+
+// takes user input, either a name, an alias or a numeric ID, and returns the matching name.
+// if nothing matches a *NotFoundError is returned with ranked suggestions
+func (idx *Index) Resolve(kind Kind, input string) (string, error) {
+	input = strings.ToLower(strings.TrimSpace(input))
+
+	idx.mux.Lock()
+	name, isAlias := idx.aliases[kind][input]
+	idx.mux.Unlock()
+
+	if isAlias {
+		return name, nil
+	}
+
+	entries, err := idx.Entries(kind)
+	if err != nil {
+		return "", err
+	}
+
+	ID, err := strconv.Atoi(input)
+	isNumber := err == nil
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Name == input || (isNumber && entry.ID == ID) {
+			return entry.Name, nil
+		}
+		names = append(names, entry.Name)
+	}
+
+	return "", &NotFoundError{
+		Kind:        kind,
+		Input:       input,
+		Suggestions: Suggest(input, names, maxSuggestions),
+	}
+}
+
+// writes the index to disk, expects the lock to be held
+func (idx *Index) save() error {
+	data, err := json.Marshal(indexFile{
+		Entries: idx.entries,
+		Aliases: idx.aliases,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(idx.path, data, 0644); err != nil {
+		return err
+	}
+
+	idx.unsaved = false
+	return nil
+}
+
+// ==================
+// Matching Functions
+// ==================
+
+// ranks the names by how closely they match the input and returns at most limit of them.
+// names starting with the input rank first, then names containing it,
+// then names within a small edit distance
+func Suggest(input string, names []string, limit int) []string {
+	type candidate struct {
+		name     string
+		tier     int
+		distance int
+	}
+
+	if input == "" {
+		return []string{}
+	}
+
+	// allow roughly one typo for every three characters typed
+	maxDistance := max(2, len(input)/3)
+
+	var candidates []candidate
+	for _, name := range names {
+		distance := Levenshtein(input, name)
+
+		switch {
+		case strings.HasPrefix(name, input):
+			candidates = append(candidates, candidate{name, 0, distance})
+		case strings.Contains(name, input):
+			candidates = append(candidates, candidate{name, 1, distance})
+		case distance <= maxDistance:
+			candidates = append(candidates, candidate{name, 2, distance})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].tier != candidates[j].tier {
+			return candidates[i].tier < candidates[j].tier
+		}
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].name < candidates[j].name
+	})
+
+	suggestions := []string{}
+	for i := 0; i < len(candidates) && i < limit; i++ {
+		suggestions = append(suggestions, candidates[i].name)
+	}
+
+	return suggestions
+}
+
+// returns the number of single character edits needed to turn a into b
+func Levenshtein(a, b string) int {
+	aRunes := []rune(a)
+	bRunes := []rune(b)
+
+	// only the previous row of the distance table is needed
+	previous := make([]int, len(bRunes)+1)
+	current := make([]int, len(bRunes)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(aRunes); i++ {
+		current[0] = i
+		for j := 1; j <= len(bRunes); j++ {
+			cost := 1
+			if aRunes[i-1] == bRunes[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(
+				previous[j]+1,      // deletion
+				current[j-1]+1,     // insertion
+				previous[j-1]+cost, // substitution
+			)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(bRunes)]
+}
@@ -0,0 +1,153 @@
+package nameindex_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	nameindex "github.com/nicholasss/pokedexcli/internal/nameindex"
+)
+
+func TestLevenshtein(t *testing.T) {
+	cases := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{a: "pikachu", b: "pikachu", expected: 0},
+		{a: "pikachoo", b: "pikachu", expected: 2},
+		{a: "", b: "eevee", expected: 5},
+		{a: "charmandr", b: "charmander", expected: 1},
+		{a: "flareon", b: "jolteon", expected: 4},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			actual := nameindex.Levenshtein(c.a, c.b)
+			if actual != c.expected {
+				t.Errorf("expected distance %d between '%s' and '%s', got %d", c.expected, c.a, c.b, actual)
+			}
+		})
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	names := []string{"pikachu", "pichu", "raichu", "pidgey", "pidgeotto", "mr-mime", "mime-jr"}
+
+	cases := []struct {
+		input    string
+		expected []string
+	}{
+		{
+			input:    "pikachoo",
+			expected: []string{"pikachu"},
+		},
+		{
+			input:    "pidg",
+			expected: []string{"pidgey", "pidgeotto"},
+		},
+		{
+			input:    "mime",
+			expected: []string{"mime-jr", "mr-mime"},
+		},
+		{
+			input:    "zzzzzzzz",
+			expected: []string{},
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			actual := nameindex.Suggest(c.input, names, 5)
+			if len(actual) != len(c.expected) {
+				t.Errorf("expected suggestions %v, got %v", c.expected, actual)
+				return
+			}
+
+			for j := range actual {
+				if actual[j] != c.expected[j] {
+					t.Errorf("expected suggestions %v, got %v", c.expected, actual)
+					return
+				}
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	// a saved index means nothing is fetched from the API
+	path := filepath.Join(t.TempDir(), "nameindex.json")
+	saved := `{
+		"entries": {"pokemon": [{"name": "bulbasaur", "id": 1}, {"name": "pikachu", "id": 25}]},
+		"aliases": {"pokemon": {"ピカチュウ": "pikachu"}}
+	}`
+	if err := os.WriteFile(path, []byte(saved), 0644); err != nil {
+		t.Errorf("unable to write index: %s", err)
+		return
+	}
+
+	index := nameindex.NewIndex(path)
+
+	cases := []struct {
+		input    string
+		expected string
+		found    bool
+	}{
+		{input: "pikachu", expected: "pikachu", found: true},
+		{input: " Bulbasaur ", expected: "bulbasaur", found: true},
+		{input: "25", expected: "pikachu", found: true},
+		{input: "ピカチュウ", expected: "pikachu", found: true},
+		{input: "pikachoo", expected: "", found: false},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			actual, err := index.Resolve(nameindex.Pokemon, c.input)
+
+			var notFound *nameindex.NotFoundError
+			if c.found && err != nil {
+				t.Errorf("expected to resolve '%s', got error: %s", c.input, err)
+				return
+			}
+			if !c.found && !errors.As(err, &notFound) {
+				t.Errorf("expected not found error for '%s', got: %v", c.input, err)
+				return
+			}
+
+			if actual != c.expected {
+				t.Errorf("expected '%s', got '%s'", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestAddAlias(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nameindex.json")
+	saved := `{"entries": {"pokemon": [{"name": "pikachu", "id": 25}]}}`
+	if err := os.WriteFile(path, []byte(saved), 0644); err != nil {
+		t.Errorf("unable to write index: %s", err)
+		return
+	}
+
+	index := nameindex.NewIndex(path)
+	index.AddAlias(nameindex.Pokemon, "Pikachu-fr", "pikachu")
+	index.AddAlias(nameindex.Pokemon, "ピカチュウ", "pikachu")
+
+	// aliases are only written to disk when flushed
+	if name, _ := nameindex.NewIndex(path).Resolve(nameindex.Pokemon, "ピカチュウ"); name != "" {
+		t.Errorf("expected the alias not to be written before flushing, got %s", name)
+		return
+	}
+
+	if err := index.Flush(); err != nil {
+		t.Errorf("unable to flush: %s", err)
+		return
+	}
+	for _, alias := range []string{"pikachu-fr", "ピカチュウ"} {
+		if name, err := nameindex.NewIndex(path).Resolve(nameindex.Pokemon, alias); name != "pikachu" {
+			t.Errorf("expected '%s' to resolve to pikachu after flushing, got '%s': %v", alias, name, err)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// paths is an internal package
+// It provides the folders where files are kept between sessions,
+// following the XDG base directory specification
+package paths
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// the folder created inside each of the base directories
+const appName = "pokedexcli"
+
+// profile and slot names become folder and file names, so they are kept simple
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// reports whether a profile or slot name is allowed
+func ValidName(name string) bool {
+	return validName.MatchString(name)
+}
+
+// returns the folder for saves and other user data
+func DataDir() (string, error) {
+	return baseDir("XDG_DATA_HOME", filepath.Join(".local", "share"))
+}
+
+// returns the folder for settings
+func ConfigDir() (string, error) {
+	return baseDir("XDG_CONFIG_HOME", ".config")
+}
+
+// returns the folder for data that can be fetched again if lost
+func CacheDir() (string, error) {
+	return baseDir("XDG_CACHE_HOME", ".cache")
+}
+
+// returns the folder holding the save slots of a profile
+func SlotDir(profile string) (string, error) {
+	if !ValidName(profile) {
+		return "", fmt.Errorf("invalid profile name '%s', use lowercase letters, numbers, '-' and '_'", profile)
+	}
+
+	dataDir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dataDir, "profiles", profile, "slots"), nil
+}
+
+// uses the environment variable when it holds an absolute path,
+// otherwise the fallback inside the home folder, as the specification says
+func baseDir(variable, fallback string) (string, error) {
+	base := os.Getenv(variable)
+
+	if !filepath.IsAbs(base) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", errors.New("unable to find home folder, set " + variable)
+		}
+		base = filepath.Join(home, fallback)
+	}
+
+	return filepath.Join(base, appName), nil
+}
@@ -5,16 +5,31 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 // =========
 // Constants
 // =========
-const LocationAreaURL = "https://pokeapi.co/api/v2/location-area"
+const BaseURL = "https://pokeapi.co/api/v2/"
+
+const LocationAreaURL = BaseURL + "location-area"
 const LocationAreaListURL = LocationAreaURL + "?offset=0&limit=20"
 const LocationAreaInfoURL = LocationAreaURL + "/" // is this needed? who knows
 
-const PokemonInfoURL = "https://pokeapi.co/api/v2/pokemon/"
+const PokemonInfoURL = BaseURL + "pokemon/"
+const PokemonSpeciesURL = BaseURL + "pokemon-species/"
+const PokemonEncountersPath = "/encounters"
+
+const GenerationURL = BaseURL + "generation/"
+const PokedexURL = BaseURL + "pokedex/"
+
+const AbilityURL = BaseURL + "ability/"
+const MoveURL = BaseURL + "move/"
+
+const ItemURL = BaseURL + "item/"
 
 // =====
 // Types
@@ -29,15 +44,45 @@ type LocationList struct {
 	} `json:"results"`
 }
 
+// The name of a resource in a single language.
+type Name struct {
+	Name     string `json:"name"`
+	Language struct {
+		Name string `json:"name"`
+	} `json:"language"`
+}
+
+// A description of a resource in a single language, from a single game version.
+type FlavorText struct {
+	FlavorText string `json:"flavor_text"`
+	Language   struct {
+		Name string `json:"name"`
+	} `json:"language"`
+	Version struct {
+		Name string `json:"name"`
+	} `json:"version"`
+}
+
+// Lists every resource of a kind, such as all Pokemon or all items.
+type ResourceList struct {
+	Count   int `json:"count"`
+	Results []struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"results"`
+}
+
 type LocationInfo struct {
 	ID          int    `json:"id"`
 	Name        string `json:"name"`
 	URL         string `json:"location.url"`
+	Names       []Name `json:"names"`
 	PokemonList []struct {
 		Pokemon struct {
 			Name string `json:"name"`
 			URL  string `json:"url"`
 		} `json:"pokemon"`
+		VersionDetails []VersionEncounters `json:"version_details"`
 	} `json:"pokemon_encounters"`
 }
 
@@ -47,7 +92,17 @@ type PokemonInfo struct {
 	Height         int    `json:"height"`
 	Weight         int    `json:"weight"`
 	BaseExperience int    `json:"base_experience"`
-	StatList       []struct {
+	Species        struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"species"`
+	Sprites struct {
+		FrontDefault string `json:"front_default"`
+		FrontShiny   string `json:"front_shiny"`
+		BackDefault  string `json:"back_default"`
+		BackShiny    string `json:"back_shiny"`
+	} `json:"sprites"`
+	StatList []struct {
 		BaseStat int `json:"base_stat"`
 		Stat     struct {
 			Name string `json:"name"`
@@ -60,6 +115,119 @@ type PokemonInfo struct {
 	} `json:"types"`
 }
 
+type PokemonSpecies struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	// the chance of being female in eighths, or -1 for genderless
+	GenderRate int `json:"gender_rate"`
+	// how easily the species is caught, from 3 to 255
+	CaptureRate       int          `json:"capture_rate"`
+	Names             []Name       `json:"names"`
+	FlavorTextEntries []FlavorText `json:"flavor_text_entries"`
+	// the pokemon of the species, such as landorus-incarnate and landorus-therian
+	Varieties []struct {
+		IsDefault bool `json:"is_default"`
+		Pokemon   struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"pokemon"`
+	} `json:"varieties"`
+}
+
+// A generation of games and the species introduced in it.
+type Generation struct {
+	ID             int    `json:"id"`
+	Name           string `json:"name"`
+	Names          []Name `json:"names"`
+	PokemonSpecies []struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"pokemon_species"`
+}
+
+// A regional Pokedex with its own numbering of the species in it.
+type RegionalPokedex struct {
+	ID             int    `json:"id"`
+	Name           string `json:"name"`
+	IsMainSeries   bool   `json:"is_main_series"`
+	Names          []Name `json:"names"`
+	PokemonEntries []struct {
+		EntryNumber    int `json:"entry_number"`
+		PokemonSpecies struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"pokemon_species"`
+	} `json:"pokemon_entries"`
+}
+
+// An ability and every Pokemon that can have it.
+type AbilityInfo struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Pokemon []struct {
+		IsHidden bool `json:"is_hidden"`
+		Pokemon  struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"pokemon"`
+	} `json:"pokemon"`
+}
+
+// A move and every Pokemon that can learn it.
+type MoveInfo struct {
+	ID               int    `json:"id"`
+	Name             string `json:"name"`
+	LearnedByPokemon []struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"learned_by_pokemon"`
+}
+
+// An item and what it costs to buy at a shop.
+type ItemInfo struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Cost  int    `json:"cost"`
+	Names []Name `json:"names"`
+}
+
+type PokemonEncounters []struct {
+	LocationArea struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"location_area"`
+	VersionDetails []VersionEncounters `json:"version_details"`
+}
+
+// The ways a pokemon can be encountered in one version of the games.
+type VersionEncounters struct {
+	MaxChance        int `json:"max_chance"`
+	EncounterDetails []struct {
+		// out of 100, for every encounter made with the method
+		Chance   int `json:"chance"`
+		MinLevel int `json:"min_level"`
+		MaxLevel int `json:"max_level"`
+		Method   struct {
+			Name string `json:"name"`
+		} `json:"method"`
+	} `json:"encounter_details"`
+	Version struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"version"`
+}
+
+// A single row of where a Pokemon can be found,
+// combining every encounter slot with the same area, version and method.
+type EncounterSummary struct {
+	LocationArea string
+	Version      string
+	Method       string
+	MinLevel     int
+	MaxLevel     int
+	Chance       int
+}
+
 // ===================
 // Unmarshal Functions
 // ===================
@@ -74,6 +242,76 @@ func UnmarshalPokemonInfo(data []byte) (PokemonInfo, error) {
 	return pokemonInfo, nil
 }
 
+// Unmarshals data to a PokemonSpecies struct.
+func UnmarshalPokemonSpecies(data []byte) (PokemonSpecies, error) {
+	var pokemonSpecies PokemonSpecies
+	if err := json.Unmarshal(data, &pokemonSpecies); err != nil {
+		return PokemonSpecies{}, fmt.Errorf("unable to unmarshal json request: %w", err)
+	}
+
+	return pokemonSpecies, nil
+}
+
+// Unmarshals data to a Generation struct.
+func UnmarshalGeneration(data []byte) (Generation, error) {
+	var generation Generation
+	if err := json.Unmarshal(data, &generation); err != nil {
+		return Generation{}, fmt.Errorf("unable to unmarshal json request: %w", err)
+	}
+
+	return generation, nil
+}
+
+// Unmarshals data to a RegionalPokedex struct.
+func UnmarshalRegionalPokedex(data []byte) (RegionalPokedex, error) {
+	var regionalPokedex RegionalPokedex
+	if err := json.Unmarshal(data, &regionalPokedex); err != nil {
+		return RegionalPokedex{}, fmt.Errorf("unable to unmarshal json request: %w", err)
+	}
+
+	return regionalPokedex, nil
+}
+
+// Unmarshals data to an AbilityInfo struct.
+func UnmarshalAbilityInfo(data []byte) (AbilityInfo, error) {
+	var abilityInfo AbilityInfo
+	if err := json.Unmarshal(data, &abilityInfo); err != nil {
+		return AbilityInfo{}, fmt.Errorf("unable to unmarshal json request: %w", err)
+	}
+
+	return abilityInfo, nil
+}
+
+// Unmarshals data to a MoveInfo struct.
+func UnmarshalMoveInfo(data []byte) (MoveInfo, error) {
+	var moveInfo MoveInfo
+	if err := json.Unmarshal(data, &moveInfo); err != nil {
+		return MoveInfo{}, fmt.Errorf("unable to unmarshal json request: %w", err)
+	}
+
+	return moveInfo, nil
+}
+
+// Unmarshals data to an ItemInfo struct.
+func UnmarshalItemInfo(data []byte) (ItemInfo, error) {
+	var itemInfo ItemInfo
+	if err := json.Unmarshal(data, &itemInfo); err != nil {
+		return ItemInfo{}, fmt.Errorf("unable to unmarshal json request: %w", err)
+	}
+
+	return itemInfo, nil
+}
+
+// Unmarshals data to a PokemonEncounters slice.
+func UnmarshalPokemonEncounters(data []byte) (PokemonEncounters, error) {
+	var pokemonEncounters PokemonEncounters
+	if err := json.Unmarshal(data, &pokemonEncounters); err != nil {
+		return PokemonEncounters{}, fmt.Errorf("unable to unmarshal json request: %w", err)
+	}
+
+	return pokemonEncounters, nil
+}
+
 // Unmarshals data to a LocationInfo struct.
 func UnmarshalLocationInfo(data []byte) (LocationInfo, error) {
 	var locationInfo LocationInfo
@@ -84,6 +322,16 @@ func UnmarshalLocationInfo(data []byte) (LocationInfo, error) {
 	return locationInfo, nil
 }
 
+// Unmarshals data to a ResourceList struct.
+func UnmarshalResourceList(data []byte) (ResourceList, error) {
+	var resourceList ResourceList
+	if err := json.Unmarshal(data, &resourceList); err != nil {
+		return ResourceList{}, fmt.Errorf("unable to unmarshal json request: %w", err)
+	}
+
+	return resourceList, nil
+}
+
 // Unmarshals data to a LocationList struct.
 func UnmarshalLocationList(data []byte) (LocationList, error) {
 	var locationList LocationList
@@ -94,6 +342,146 @@ func UnmarshalLocationList(data []byte) (LocationList, error) {
 	return locationList, nil
 }
 
+// ======================
+// Localization Functions
+// ======================
+
+// the language used when a name is missing in the requested language
+const FallbackLanguage = "en"
+
+// returns the name in the given language, falling back to English
+// and then to the slug when neither is available
+func LocalizedName(names []Name, language, slug string) string {
+	fallback := slug
+	for _, name := range names {
+		if name.Language.Name == language {
+			return name.Name
+		}
+		if name.Language.Name == FallbackLanguage {
+			fallback = name.Name
+		}
+	}
+
+	return fallback
+}
+
+// returns the name of the pokemon a species is by default, such as
+// landorus-incarnate for landorus, or the species name when none is listed
+func DefaultVariety(species PokemonSpecies) string {
+	for _, variety := range species.Varieties {
+		if variety.IsDefault {
+			return variety.Pokemon.Name
+		}
+	}
+
+	return species.Name
+}
+
+// returns the most recent description in the given language, falling back to English.
+// the API keeps the line breaks from the games, these are replaced with spaces
+func LocalizedFlavorText(entries []FlavorText, language string) string {
+	var text, fallback string
+	for _, entry := range entries {
+		if entry.Language.Name == language {
+			text = entry.FlavorText
+		}
+		if entry.Language.Name == FallbackLanguage {
+			fallback = entry.FlavorText
+		}
+	}
+
+	if text == "" {
+		text = fallback
+	}
+
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// ==============
+// Stat Functions
+// ==============
+
+// the highest value a base stat can have
+const MaxBaseStat = 255
+
+// adds up every base stat of the pokemon
+func BaseStatTotal(pokemon PokemonInfo) int {
+	total := 0
+	for _, stat := range pokemon.StatList {
+		total += stat.BaseStat
+	}
+
+	return total
+}
+
+// =================
+// Summary Functions
+// =================
+
+// flattens the encounters into one summary per area, version and method.
+// chances for the same method are added together and the level range is widened,
+// the result is sorted by chance with the most likely encounters first
+func SummarizeEncounters(encounters PokemonEncounters) []EncounterSummary {
+	type summaryKey struct {
+		area, version, method string
+	}
+
+	index := make(map[summaryKey]int)
+	var summaries []EncounterSummary
+
+	for _, area := range encounters {
+		for _, version := range area.VersionDetails {
+			for _, detail := range version.EncounterDetails {
+				key := summaryKey{area.LocationArea.Name, version.Version.Name, detail.Method.Name}
+
+				i, exists := index[key]
+				if !exists {
+					index[key] = len(summaries)
+					summaries = append(summaries, EncounterSummary{
+						LocationArea: key.area,
+						Version:      key.version,
+						Method:       key.method,
+						MinLevel:     detail.MinLevel,
+						MaxLevel:     detail.MaxLevel,
+						Chance:       detail.Chance,
+					})
+					continue
+				}
+
+				summary := &summaries[i]
+				summary.Chance += detail.Chance
+				summary.MinLevel = min(summary.MinLevel, detail.MinLevel)
+				summary.MaxLevel = max(summary.MaxLevel, detail.MaxLevel)
+			}
+		}
+	}
+
+	sort.SliceStable(summaries, func(i, j int) bool {
+		if summaries[i].Chance != summaries[j].Chance {
+			return summaries[i].Chance > summaries[j].Chance
+		}
+		if summaries[i].LocationArea != summaries[j].LocationArea {
+			return summaries[i].LocationArea < summaries[j].LocationArea
+		}
+		return summaries[i].Version < summaries[j].Version
+	})
+
+	return summaries
+}
+
+// takes a resource URL such as ".../pokemon/25/" and returns its ID
+func IDFromURL(URL string) (int, error) {
+	trimmed := strings.TrimSuffix(URL, "/")
+	lastSlash := strings.LastIndex(trimmed, "/")
+
+	ID, err := strconv.Atoi(trimmed[lastSlash+1:])
+	if err != nil {
+		return 0, fmt.Errorf("unable to find ID in URL '%s': %w", URL, err)
+	}
+
+	return ID, nil
+}
+
 // =================
 // Network Functions
 // =================
@@ -106,6 +494,10 @@ func RequestGETBody(URL string) ([]byte, error) {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode >= 400 {
+		return []byte{}, fmt.Errorf("GET with address '%s' failed with status '%s'", URL, resp.Status)
+	}
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return []byte{}, fmt.Errorf("unable to ReadAll from response body: %w", err)
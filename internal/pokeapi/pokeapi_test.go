@@ -44,3 +44,149 @@ func TestGetBody(t *testing.T) {
 		}
 	}
 }
+
+func TestSummarizeEncounters(t *testing.T) {
+	data := []byte(`[
+		{
+			"location_area": {"name": "viridian-forest-area"},
+			"version_details": [
+				{
+					"version": {"name": "red"},
+					"encounter_details": [
+						{"chance": 5, "min_level": 3, "max_level": 3, "method": {"name": "walk"}},
+						{"chance": 5, "min_level": 5, "max_level": 5, "method": {"name": "walk"}}
+					]
+				}
+			]
+		},
+		{
+			"location_area": {"name": "power-plant-area"},
+			"version_details": [
+				{
+					"version": {"name": "red"},
+					"encounter_details": [
+						{"chance": 25, "min_level": 21, "max_level": 24, "method": {"name": "walk"}}
+					]
+				}
+			]
+		}
+	]`)
+
+	encounters, err := pokeapi.UnmarshalPokemonEncounters(data)
+	if err != nil {
+		t.Errorf("unable to unmarshal encounters: %s", err)
+		return
+	}
+
+	expected := []pokeapi.EncounterSummary{
+		{LocationArea: "power-plant-area", Version: "red", Method: "walk", MinLevel: 21, MaxLevel: 24, Chance: 25},
+		{LocationArea: "viridian-forest-area", Version: "red", Method: "walk", MinLevel: 3, MaxLevel: 5, Chance: 10},
+	}
+
+	actual := pokeapi.SummarizeEncounters(encounters)
+	if len(actual) != len(expected) {
+		t.Errorf("expected %d summaries, got %d", len(expected), len(actual))
+		return
+	}
+
+	for i := range actual {
+		if actual[i] != expected[i] {
+			t.Errorf("summary %d: expected %+v, got %+v", i, expected[i], actual[i])
+		}
+	}
+}
+
+func TestLocalizedName(t *testing.T) {
+	data := []byte(`{
+		"name": "pikachu",
+		"names": [
+			{"name": "ピカチュウ", "language": {"name": "ja"}},
+			{"name": "Pikachu", "language": {"name": "en"}}
+		],
+		"flavor_text_entries": [
+			{"flavor_text": "When several of\nthese POKéMON\fgather.", "language": {"name": "en"}}
+		]
+	}`)
+
+	species, err := pokeapi.UnmarshalPokemonSpecies(data)
+	if err != nil {
+		t.Errorf("unable to unmarshal species: %s", err)
+		return
+	}
+
+	cases := []struct {
+		language string
+		expected string
+	}{
+		{language: "ja", expected: "ピカチュウ"},
+		{language: "en", expected: "Pikachu"},
+		{language: "fr", expected: "Pikachu"},
+	}
+
+	for _, c := range cases {
+		actual := pokeapi.LocalizedName(species.Names, c.language, species.Name)
+		if actual != c.expected {
+			t.Errorf("expected '%s' for language '%s', got '%s'", c.expected, c.language, actual)
+		}
+	}
+
+	actual := pokeapi.LocalizedName(nil, "ja", "pikachu")
+	if actual != "pikachu" {
+		t.Errorf("expected the slug without any names, got '%s'", actual)
+	}
+
+	description := pokeapi.LocalizedFlavorText(species.FlavorTextEntries, "ja")
+	if description != "When several of these POKéMON gather." {
+		t.Errorf("expected the english description with spaces, got '%s'", description)
+	}
+}
+
+func TestDefaultVariety(t *testing.T) {
+	data := []byte(`{
+		"name": "landorus",
+		"varieties": [
+			{"is_default": true, "pokemon": {"name": "landorus-incarnate"}},
+			{"is_default": false, "pokemon": {"name": "landorus-therian"}}
+		]
+	}`)
+
+	species, err := pokeapi.UnmarshalPokemonSpecies(data)
+	if err != nil {
+		t.Errorf("unable to unmarshal species: %s", err)
+		return
+	}
+
+	if actual := pokeapi.DefaultVariety(species); actual != "landorus-incarnate" {
+		t.Errorf("expected landorus-incarnate, got %s", actual)
+	}
+	if actual := pokeapi.DefaultVariety(pokeapi.PokemonSpecies{Name: "pikachu"}); actual != "pikachu" {
+		t.Errorf("expected the species name without varieties, got %s", actual)
+	}
+}
+
+func TestBaseStatTotal(t *testing.T) {
+	data := []byte(`{
+		"name": "pikachu",
+		"stats": [
+			{"base_stat": 35, "stat": {"name": "hp"}},
+			{"base_stat": 55, "stat": {"name": "attack"}},
+			{"base_stat": 40, "stat": {"name": "defense"}},
+			{"base_stat": 50, "stat": {"name": "special-attack"}},
+			{"base_stat": 50, "stat": {"name": "special-defense"}},
+			{"base_stat": 90, "stat": {"name": "speed"}}
+		]
+	}`)
+
+	pokemon, err := pokeapi.UnmarshalPokemonInfo(data)
+	if err != nil {
+		t.Errorf("unable to unmarshal pokemon: %s", err)
+		return
+	}
+
+	if actual := pokeapi.BaseStatTotal(pokemon); actual != 320 {
+		t.Errorf("expected 320, got %d", actual)
+	}
+	if actual := pokeapi.BaseStatTotal(pokeapi.PokemonInfo{}); actual != 0 {
+		t.Errorf("expected 0 without stats, got %d", actual)
+	}
+}
@@ -70,6 +70,15 @@ func (c *Cache) Get(name string) ([]byte, bool) {
 	return foundEntry.val, true
 }
 
+// Removes an entry from the cache, if there is one,
+// so the next request for it goes to the API again
+func (c *Cache) Remove(name string) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	delete(c.entries, name)
+}
+
 // removes expired cache entries
 //
 // called when the cache is created by NewCache
@@ -75,3 +75,20 @@ func TestReadLoop(t *testing.T) {
 		return
 	}
 }
+
+func TestRemove(t *testing.T) {
+	cache := pokecache.NewCache(5 * time.Second)
+	cache.Add("https://example.com", []byte("old"))
+	cache.Remove("https://example.com")
+
+	if _, ok := cache.Get("https://example.com"); ok {
+		t.Errorf("expected key to be removed")
+		return
+	}
+
+	// an entry that was removed can be added again with new data
+	cache.Add("https://example.com", []byte("new"))
+	if val, _ := cache.Get("https://example.com"); string(val) != "new" {
+		t.Errorf("expected the new value, got %s", val)
+	}
+}
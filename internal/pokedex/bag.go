@@ -0,0 +1,225 @@
+package pokedex
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// items other than balls, named as in the PokeAPI
+const (
+	Potion      = "potion"
+	SuperPotion = "super-potion"
+	HyperPotion = "hyper-potion"
+	Revive      = "revive"
+)
+
+// the pockets of the bag, in the order they are shown
+const (
+	BallPocket     = "Poke Balls"
+	MedicinePocket = "Medicine"
+	ItemPocket     = "Items"
+)
+
+// the most of one item the bag can hold, as in the games
+const MaxQuantity = 999
+
+var medicine = []string{Potion, SuperPotion, HyperPotion, Revive}
+
+// An item in the bag and how many of it there are.
+type Item struct {
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
+}
+
+// returns the pocket of the bag an item is kept in
+func Pocket(name string) string {
+	switch {
+	case slices.Contains(Balls, Ball(name)):
+		return BallPocket
+	case slices.Contains(medicine, name):
+		return MedicinePocket
+	}
+
+	return ItemPocket
+}
+
+// returns the name of an item for display, such as "Super Potion"
+func ItemName(name string) string {
+	words := strings.Split(name, "-")
+	for i, word := range words {
+		if word != "" {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// returns what a new trainer starts with
+func StarterBag() []Item {
+	return []Item{
+		{Name: string(PokeBall), Quantity: 10},
+		{Name: Potion, Quantity: 3},
+	}
+}
+
+// what can be found when exploring an area for the first time, and how often
+var foundItems = []struct {
+	item   Item
+	weight int
+}{
+	{item: Item{Name: string(PokeBall), Quantity: 3}, weight: 40},
+	{item: Item{Name: string(GreatBall), Quantity: 2}, weight: 20},
+	{item: Item{Name: Potion, Quantity: 2}, weight: 20},
+	{item: Item{Name: SuperPotion, Quantity: 1}, weight: 8},
+	{item: Item{Name: string(UltraBall), Quantity: 1}, weight: 6},
+	{item: Item{Name: string(QuickBall), Quantity: 1}, weight: 3},
+	{item: Item{Name: string(DuskBall), Quantity: 1}, weight: 3},
+}
+
+// picks the item found when exploring an area for the first time
+func FindItem(source *Source) Item {
+	total := 0
+	for _, found := range foundItems {
+		total += found.weight
+	}
+
+	roll := source.Intn(total)
+	for _, found := range foundItems {
+		if roll < found.weight {
+			return found.item
+		}
+		roll -= found.weight
+	}
+
+	return foundItems[0].item
+}
+
+// returns every item in the bag, by pocket and then by name
+func (p *Pokedex) Bag() []Item {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	return p.bagItems()
+}
+
+// expects the lock to be held
+func (p *Pokedex) bagItems() []Item {
+	items := make([]Item, 0, len(p.bag))
+	for name, quantity := range p.bag {
+		items = append(items, Item{Name: name, Quantity: quantity})
+	}
+
+	pockets := map[string]int{BallPocket: 0, MedicinePocket: 1, ItemPocket: 2}
+	sort.Slice(items, func(i, j int) bool {
+		first, second := pockets[Pocket(items[i].Name)], pockets[Pocket(items[j].Name)]
+		if first != second {
+			return first < second
+		}
+		return items[i].Name < items[j].Name
+	})
+
+	return items
+}
+
+// returns how many of the item are in the bag
+func (p *Pokedex) ItemCount(name string) int {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	return p.bag[name]
+}
+
+// puts a number of the item in the bag, up to the most it can hold.
+// returns how many were put in
+func (p *Pokedex) AddItem(name string, quantity int) int {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	added := min(quantity, MaxQuantity-p.bag[name])
+	if added <= 0 {
+		return 0
+	}
+
+	p.bag[name] += added
+	p.version++
+	return added
+}
+
+// takes a number of the item out of the bag, nothing is taken when there are not enough
+func (p *Pokedex) UseItem(name string, quantity int) error {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	if p.bag[name] < quantity {
+		return fmt.Errorf("not enough %s, %d left", ItemName(name), p.bag[name])
+	}
+
+	p.bag[name] -= quantity
+	if p.bag[name] == 0 {
+		delete(p.bag, name)
+	}
+	p.version++
+
+	return nil
+}
+
+// replaces everything in the bag, for loading from save
+func (p *Pokedex) SetBag(items []Item) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	p.bag = make(map[string]int)
+	for _, item := range items {
+		if item.Quantity > 0 {
+			p.bag[item.Name] = min(p.bag[item.Name]+item.Quantity, MaxQuantity)
+		}
+	}
+	p.version++
+}
+
+// records an area as explored, reports whether it was the first time
+func (p *Pokedex) MarkExplored(area string) bool {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	if area == "" || p.explored[area] {
+		return false
+	}
+
+	p.explored[area] = true
+	p.version++
+	return true
+}
+
+// replaces every explored area, for loading from save
+func (p *Pokedex) ReplaceExplored(areas []string) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	p.explored = make(map[string]bool)
+	for _, area := range areas {
+		p.explored[area] = true
+	}
+	p.version++
+}
+
+// returns every explored area sorted by name
+func (p *Pokedex) Explored() []string {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	return p.exploredList()
+}
+
+// expects the lock to be held
+func (p *Pokedex) exploredList() []string {
+	areas := make([]string, 0, len(p.explored))
+	for area := range p.explored {
+		areas = append(areas, area)
+	}
+	sort.Strings(areas)
+
+	return areas
+}
@@ -0,0 +1,255 @@
+package pokedex
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	pokeapi "github.com/nicholasss/pokedexcli/internal/pokeapi"
+)
+
+// the catch engine follows the formula of the fourth generation games
+
+// =========
+// Constants
+// =========
+
+// A kind of Poke Ball, named as in the PokeAPI.
+type Ball string
+
+const (
+	PokeBall    Ball = "poke-ball"
+	GreatBall   Ball = "great-ball"
+	UltraBall   Ball = "ultra-ball"
+	MasterBall  Ball = "master-ball"
+	PremierBall Ball = "premier-ball"
+	QuickBall   Ball = "quick-ball"
+	DuskBall    Ball = "dusk-ball"
+	NetBall     Ball = "net-ball"
+	RepeatBall  Ball = "repeat-ball"
+)
+
+// every ball that can be thrown, in the order they are listed to the user
+var Balls = []Ball{PokeBall, GreatBall, UltraBall, MasterBall, PremierBall, QuickBall, DuskBall, NetBall, RepeatBall}
+
+// A status condition of the wild pokemon.
+type Status string
+
+const (
+	NoStatus  Status = ""
+	Sleep     Status = "sleep"
+	Freeze    Status = "freeze"
+	Paralysis Status = "paralysis"
+	Poison    Status = "poison"
+	Burn      Status = "burn"
+)
+
+// every status condition, in the order they are listed to the user
+var Statuses = []Status{Sleep, Freeze, Paralysis, Poison, Burn}
+
+const (
+	// a catch value this high is always caught
+	maxCatchValue = 255
+	// each shake check passes when a number below 65536 is under the shake probability
+	shakeChecks = 4
+	shakeRange  = 65536
+	// how long the ball takes to shake
+	shakeDelay = 500 * time.Millisecond
+)
+
+// =====
+// Types
+// =====
+
+// Everything about a throw that changes the chance of a catch.
+type CatchAttempt struct {
+	// from the species, between 3 for legendaries and 255 for the most common pokemon
+	CaptureRate int
+	Ball        Ball
+	// the remaining HP of the wild pokemon as a percentage,
+	// 100 or left at zero when it is not hurt
+	HPPercent int
+	Status    Status
+	// the turn of the encounter the ball is thrown on, starting at 1
+	Turn int
+	// the time of day the ball is thrown
+	ThrownAt time.Time
+	// the types of the wild pokemon
+	Types []string
+	// the wild pokemon is in a cave
+	InCave bool
+	// the species was caught before
+	AlreadyCaught bool
+}
+
+// What happened after a ball was thrown.
+type CatchResult struct {
+	// the number of times the ball shook, a caught pokemon shakes every time
+	Shakes int
+	Caught bool
+}
+
+// ==============
+// Ball Functions
+// ==============
+
+// finds the ball by a name such as "ultra", "ultra-ball" or "Ultra Ball"
+func ParseBall(name string) (Ball, error) {
+	slug := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "-")
+	if !strings.HasSuffix(slug, "-ball") {
+		slug += "-ball"
+	}
+
+	ball := Ball(slug)
+	if !slices.Contains(Balls, ball) {
+		return "", fmt.Errorf("unknown ball '%s'", name)
+	}
+
+	return ball, nil
+}
+
+// returns the name of the ball for display, such as "Ultra Ball"
+func (b Ball) String() string {
+	return ItemName(string(b))
+}
+
+// returns how much the ball multiplies the catch value for the throw
+func (b Ball) Modifier(attempt CatchAttempt) float64 {
+	switch b {
+	case GreatBall:
+		return 1.5
+	case UltraBall:
+		return 2
+	case MasterBall:
+		return maxCatchValue
+	case QuickBall:
+		if attempt.Turn <= 1 {
+			return 4
+		}
+	case DuskBall:
+		hour := attempt.ThrownAt.Hour()
+		if attempt.InCave || hour >= 20 || hour < 6 {
+			return 3.5
+		}
+	case NetBall:
+		if slices.Contains(attempt.Types, "water") || slices.Contains(attempt.Types, "bug") {
+			return 3
+		}
+	case RepeatBall:
+		if attempt.AlreadyCaught {
+			return 3
+		}
+	}
+
+	return 1
+}
+
+// finds the status by name
+func ParseStatus(name string) (Status, error) {
+	status := Status(strings.ToLower(strings.TrimSpace(name)))
+	if status != NoStatus && !slices.Contains(Statuses, status) {
+		return "", fmt.Errorf("unknown status '%s'", name)
+	}
+
+	return status, nil
+}
+
+// returns how much the status multiplies the catch value
+func (s Status) Modifier() float64 {
+	switch s {
+	case Sleep, Freeze:
+		return 2
+	case Paralysis, Poison, Burn:
+		return 1.5
+	}
+
+	return 1
+}
+
+// ===============
+// Catch Functions
+// ===============
+
+// returns the catch value of the throw, a value of 255 or more is always caught
+func CatchValue(attempt CatchAttempt) float64 {
+	if attempt.Ball == MasterBall {
+		return maxCatchValue
+	}
+
+	// an HP left out is full HP, rather than the lowest HP
+	hp := 100.0
+	if attempt.HPPercent > 0 {
+		hp = float64(min(attempt.HPPercent, 100))
+	}
+
+	// lower HP raises the value, up to three times at the lowest HP
+	hpFactor := (300 - 2*hp) / 300
+
+	return hpFactor * float64(attempt.CaptureRate) * attempt.Ball.Modifier(attempt) * attempt.Status.Modifier()
+}
+
+// returns the number below which each shake check passes, out of 65536
+func ShakeProbability(catchValue float64) int {
+	if catchValue >= maxCatchValue {
+		return shakeRange
+	}
+	if catchValue <= 0 {
+		return 0
+	}
+
+	return int(1048560 / math.Sqrt(math.Sqrt(16711680/catchValue)))
+}
+
+// throws the ball, checking each shake until the pokemon breaks free or is caught
+func Throw(source *Source, attempt CatchAttempt) CatchResult {
+	// the master ball never fails, whatever the catch value works out to
+	if attempt.Ball == MasterBall {
+		return CatchResult{Shakes: shakeChecks, Caught: true}
+	}
+
+	probability := ShakeProbability(CatchValue(attempt))
+
+	var result CatchResult
+	for result.Shakes < shakeChecks {
+		if source.Intn(shakeRange) >= probability {
+			return result
+		}
+		result.Shakes++
+	}
+
+	result.Caught = true
+	return result
+}
+
+// what is said when the pokemon breaks free after a number of shakes
+var escapeMessages = []string{
+	"Oh no! %s broke free!",
+	"Aww! It appeared to be caught! %s broke free!",
+	"Aargh! Almost had it! %s broke free!",
+	"Gah! It was so close, too! %s broke free!",
+}
+
+// will perform an attempt to 'catch' a pokemon,
+// printing each shake of the ball
+func AttemptCatch(source *Source, pokemon pokeapi.PokemonInfo, attempt CatchAttempt) bool {
+	fmt.Printf("Throwing a %s at %s...\n", attempt.Ball, pokemon.Name)
+
+	result := Throw(source, attempt)
+
+	// the ball shakes three times at most, the last check is the catch itself
+	for shake := 1; shake <= min(result.Shakes, shakeChecks-1); shake++ {
+		source.Sleep(shakeDelay)
+		fmt.Printf("...shake %d...\n", shake)
+	}
+	source.Sleep(shakeDelay)
+
+	if result.Caught {
+		fmt.Printf("Gotcha! %s was caught!\n", pokemon.Name)
+		return true
+	}
+
+	fmt.Printf(escapeMessages[min(result.Shakes, len(escapeMessages)-1)]+"\n", pokemon.Name)
+	return false
+}
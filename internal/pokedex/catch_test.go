@@ -0,0 +1,266 @@
+package pokedex_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	pokeapi "github.com/nicholasss/pokedexcli/internal/pokeapi"
+	pokedex "github.com/nicholasss/pokedexcli/internal/pokedex"
+)
+
+// a clock stopped at one time, that counts waits instead of waiting
+type fixedClock struct {
+	at    time.Time
+	slept []time.Duration
+}
+
+func (c *fixedClock) Now() time.Time        { return c.at }
+func (c *fixedClock) Sleep(d time.Duration) { c.slept = append(c.slept, d) }
+
+// returns a seeded source with a clock stopped at noon
+func testSource(seed int64) (*pokedex.Source, *fixedClock) {
+	clock := &fixedClock{at: time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)}
+	return pokedex.NewSource(seed, clock), clock
+}
+
+func TestCatchValue(t *testing.T) {
+	noon := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	night := time.Date(2025, 1, 10, 22, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		attempt  pokedex.CatchAttempt
+		expected float64
+	}{
+		{
+			attempt:  pokedex.CatchAttempt{CaptureRate: 45, Ball: pokedex.PokeBall, HPPercent: 100},
+			expected: 15,
+		},
+		{
+			attempt:  pokedex.CatchAttempt{CaptureRate: 45, Ball: pokedex.UltraBall, HPPercent: 1},
+			expected: 89.4,
+		},
+		{
+			attempt:  pokedex.CatchAttempt{CaptureRate: 190, Ball: pokedex.GreatBall, HPPercent: 50, Status: pokedex.Sleep},
+			expected: 380,
+		},
+		{
+			attempt:  pokedex.CatchAttempt{CaptureRate: 45, Ball: pokedex.PokeBall, HPPercent: 100, Status: pokedex.Burn},
+			expected: 22.5,
+		},
+		{
+			attempt:  pokedex.CatchAttempt{CaptureRate: 3, Ball: pokedex.MasterBall, HPPercent: 100},
+			expected: 255,
+		},
+		// HP left out is treated as not hurt, and HP over 100 as full
+		{
+			attempt:  pokedex.CatchAttempt{CaptureRate: 45, Ball: pokedex.PokeBall},
+			expected: 15,
+		},
+		{
+			attempt:  pokedex.CatchAttempt{CaptureRate: 45, Ball: pokedex.PokeBall, HPPercent: 150},
+			expected: 15,
+		},
+		{
+			attempt:  pokedex.CatchAttempt{CaptureRate: 255, Ball: pokedex.MasterBall, HPPercent: 1, Status: pokedex.Sleep},
+			expected: 255,
+		},
+		{
+			attempt:  pokedex.CatchAttempt{CaptureRate: 45, Ball: pokedex.QuickBall, HPPercent: 100, Turn: 1},
+			expected: 60,
+		},
+		{
+			attempt:  pokedex.CatchAttempt{CaptureRate: 45, Ball: pokedex.QuickBall, HPPercent: 100, Turn: 2},
+			expected: 15,
+		},
+		{
+			attempt:  pokedex.CatchAttempt{CaptureRate: 45, Ball: pokedex.DuskBall, HPPercent: 100, ThrownAt: night},
+			expected: 52.5,
+		},
+		{
+			attempt:  pokedex.CatchAttempt{CaptureRate: 45, Ball: pokedex.DuskBall, HPPercent: 100, ThrownAt: noon},
+			expected: 15,
+		},
+		{
+			attempt:  pokedex.CatchAttempt{CaptureRate: 45, Ball: pokedex.DuskBall, HPPercent: 100, ThrownAt: noon, InCave: true},
+			expected: 52.5,
+		},
+		{
+			attempt:  pokedex.CatchAttempt{CaptureRate: 45, Ball: pokedex.NetBall, HPPercent: 100, Types: []string{"bug", "poison"}},
+			expected: 45,
+		},
+		{
+			attempt:  pokedex.CatchAttempt{CaptureRate: 45, Ball: pokedex.NetBall, HPPercent: 100, Types: []string{"fire"}},
+			expected: 15,
+		},
+		{
+			attempt:  pokedex.CatchAttempt{CaptureRate: 45, Ball: pokedex.RepeatBall, HPPercent: 100, AlreadyCaught: true},
+			expected: 45,
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			actual := pokedex.CatchValue(c.attempt)
+			if math.Abs(actual-c.expected) > 1e-9 {
+				t.Errorf("expected catch value %v, got %v", c.expected, actual)
+				return
+			}
+		})
+	}
+}
+
+func TestShakeProbability(t *testing.T) {
+	cases := []struct {
+		catchValue float64
+		expected   int
+	}{
+		{catchValue: 0, expected: 0},
+		{catchValue: 1, expected: 16399},
+		{catchValue: 15, expected: 32274},
+		{catchValue: 100, expected: 51860},
+		{catchValue: 254.9, expected: 65528},
+		// always caught
+		{catchValue: 255, expected: 65536},
+		{catchValue: 1000, expected: 65536},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			actual := pokedex.ShakeProbability(c.catchValue)
+			if actual != c.expected {
+				t.Errorf("expected shake probability %d, got %d", c.expected, actual)
+				return
+			}
+		})
+	}
+}
+
+func TestThrow(t *testing.T) {
+	common := pokedex.CatchAttempt{CaptureRate: 45, Ball: pokedex.PokeBall, HPPercent: 100}
+	weakened := pokedex.CatchAttempt{CaptureRate: 45, Ball: pokedex.UltraBall, HPPercent: 10, Status: pokedex.Sleep}
+	legendary := pokedex.CatchAttempt{CaptureRate: 3, Ball: pokedex.PokeBall, HPPercent: 100}
+	master := pokedex.CatchAttempt{CaptureRate: 3, Ball: pokedex.MasterBall, HPPercent: 100}
+
+	cases := []struct {
+		seed     int64
+		attempt  pokedex.CatchAttempt
+		expected pokedex.CatchResult
+	}{
+		{seed: 1, attempt: common, expected: pokedex.CatchResult{Shakes: 0}},
+		{seed: 3, attempt: common, expected: pokedex.CatchResult{Shakes: 4, Caught: true}},
+		{seed: 5, attempt: common, expected: pokedex.CatchResult{Shakes: 1}},
+		{seed: 7, attempt: common, expected: pokedex.CatchResult{Shakes: 2}},
+		{seed: 1, attempt: weakened, expected: pokedex.CatchResult{Shakes: 4, Caught: true}},
+		{seed: 4, attempt: weakened, expected: pokedex.CatchResult{Shakes: 1}},
+		{seed: 3, attempt: legendary, expected: pokedex.CatchResult{Shakes: 0}},
+		{seed: 2, attempt: master, expected: pokedex.CatchResult{Shakes: 4, Caught: true}},
+		{seed: 6, attempt: master, expected: pokedex.CatchResult{Shakes: 4, Caught: true}},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			source, _ := testSource(c.seed)
+			actual := pokedex.Throw(source, c.attempt)
+			if actual != c.expected {
+				t.Errorf("expected %+v, got %+v", c.expected, actual)
+				return
+			}
+		})
+	}
+}
+
+func TestAttemptCatch(t *testing.T) {
+	pokemon := pokeapi.PokemonInfo{Name: "pikachu"}
+	attempt := pokedex.CatchAttempt{CaptureRate: 45, Ball: pokedex.PokeBall, HPPercent: 100}
+
+	cases := []struct {
+		seed     int64
+		expected bool
+		// one wait per shake shown, and one before the outcome
+		waits int
+	}{
+		{seed: 1, expected: false, waits: 1},
+		{seed: 3, expected: true, waits: 4},
+		{seed: 7, expected: false, waits: 3},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			source, clock := testSource(c.seed)
+			actual := pokedex.AttemptCatch(source, pokemon, attempt)
+			if actual != c.expected {
+				t.Errorf("expected caught to be %v, got %v", c.expected, actual)
+				return
+			}
+			if len(clock.slept) != c.waits {
+				t.Errorf("expected %d waits, got %d", c.waits, len(clock.slept))
+				return
+			}
+		})
+	}
+}
+
+func TestRollGender(t *testing.T) {
+	cases := []struct {
+		seed       int64
+		genderRate int
+		expected   []string
+	}{
+		{seed: 1, genderRate: 4, expected: []string{"female", "male", "male"}},
+		{seed: 3, genderRate: 4, expected: []string{"female", "female", "female"}},
+		{seed: 3, genderRate: 0, expected: []string{"male", "male", "male"}},
+		{seed: 3, genderRate: 8, expected: []string{"female", "female", "female"}},
+		{seed: 1, genderRate: -1, expected: []string{"genderless", "genderless", "genderless"}},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			source, _ := testSource(c.seed)
+			for j, expected := range c.expected {
+				actual := pokedex.RollGender(source, c.genderRate)
+				if actual != expected {
+					t.Errorf("expected roll %d to be %s, got %s", j, expected, actual)
+					return
+				}
+			}
+		})
+	}
+}
+
+func TestParseBall(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected pokedex.Ball
+		display  string
+		err      bool
+	}{
+		{input: "ultra", expected: pokedex.UltraBall, display: "Ultra Ball"},
+		{input: "ultra-ball", expected: pokedex.UltraBall, display: "Ultra Ball"},
+		{input: "Quick Ball", expected: pokedex.QuickBall, display: "Quick Ball"},
+		{input: "poke", expected: pokedex.PokeBall, display: "Poke Ball"},
+		{input: "lure", err: true},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			actual, err := pokedex.ParseBall(c.input)
+			if c.err {
+				if err == nil {
+					t.Errorf("expected an error for '%s', got %s", c.input, actual)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("unexpected error: %s", err)
+				return
+			}
+			if actual != c.expected || actual.String() != c.display {
+				t.Errorf("expected %s (%s), got %s (%s)", c.expected, c.display, actual, actual.String())
+				return
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package pokedex
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	pokeapi "github.com/nicholasss/pokedexcli/internal/pokeapi"
+)
+
+// the method used when none is given, walking in tall grass
+const DefaultMethod = "walk"
+
+// other names for encounter methods, by the name used in the PokeAPI
+var methodAliases = map[string]string{
+	"grass":   "walk",
+	"water":   "surf",
+	"fish":    "old-rod",
+	"fishing": "old-rod",
+	"rod":     "old-rod",
+}
+
+// A way a pokemon can be encountered in an area, such as walking in grass at levels 3 to 5.
+type EncounterSlot struct {
+	Pokemon  string
+	Version  string
+	Method   string
+	MinLevel int
+	MaxLevel int
+	// out of 100, for every encounter made with the method
+	Chance int
+}
+
+// A wild pokemon that appeared.
+type Encounter struct {
+	Pokemon string
+	Level   int
+	Method  string
+	Version string
+}
+
+// returns the method used in the PokeAPI for a name such as "grass" or "surf"
+func ParseMethod(name string) string {
+	name = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "-")
+	if name == "" {
+		return DefaultMethod
+	}
+	if method, ok := methodAliases[name]; ok {
+		return method
+	}
+	return name
+}
+
+// returns every method pokemon can be encountered with in the area, sorted by name
+func EncounterMethods(area pokeapi.LocationInfo) []string {
+	var methods []string
+	for _, pokemon := range area.PokemonList {
+		for _, version := range pokemon.VersionDetails {
+			for _, detail := range version.EncounterDetails {
+				if !slices.Contains(methods, detail.Method.Name) {
+					methods = append(methods, detail.Method.Name)
+				}
+			}
+		}
+	}
+	sort.Strings(methods)
+
+	return methods
+}
+
+// returns every slot of the area for the method in one version of the games.
+// when no version is given, the newest version with the method is used,
+// so that chances from different games are not mixed
+func EncounterSlots(area pokeapi.LocationInfo, method, version string) []EncounterSlot {
+	if version == "" {
+		version = newestVersion(area, method)
+	}
+
+	var slots []EncounterSlot
+	for _, pokemon := range area.PokemonList {
+		for _, versionDetails := range pokemon.VersionDetails {
+			if versionDetails.Version.Name != version {
+				continue
+			}
+
+			for _, detail := range versionDetails.EncounterDetails {
+				if detail.Method.Name != method {
+					continue
+				}
+
+				slots = append(slots, EncounterSlot{
+					Pokemon:  pokemon.Pokemon.Name,
+					Version:  version,
+					Method:   method,
+					MinLevel: detail.MinLevel,
+					MaxLevel: detail.MaxLevel,
+					Chance:   detail.Chance,
+				})
+			}
+		}
+	}
+
+	return slots
+}
+
+// returns the version with the highest ID that has encounters with the method
+func newestVersion(area pokeapi.LocationInfo, method string) string {
+	newest, newestID := "", -1
+	for _, pokemon := range area.PokemonList {
+		for _, version := range pokemon.VersionDetails {
+			ID, err := pokeapi.IDFromURL(version.Version.URL)
+			if err != nil || ID <= newestID {
+				continue
+			}
+
+			for _, detail := range version.EncounterDetails {
+				if detail.Method.Name == method {
+					newest, newestID = version.Version.Name, ID
+					break
+				}
+			}
+		}
+	}
+
+	return newest
+}
+
+// picks a wild pokemon from the slots, weighted by their chances,
+// and a level within the range of the slot picked.
+// reports false when there are no slots to pick from
+func RollEncounter(source *Source, slots []EncounterSlot) (Encounter, bool) {
+	total := 0
+	for _, slot := range slots {
+		total += max(slot.Chance, 0)
+	}
+	if total == 0 {
+		return Encounter{}, false
+	}
+
+	roll := source.Intn(total)
+	for _, slot := range slots {
+		if roll >= max(slot.Chance, 0) {
+			roll -= max(slot.Chance, 0)
+			continue
+		}
+
+		level := slot.MinLevel
+		if slot.MaxLevel > slot.MinLevel {
+			level += source.Intn(slot.MaxLevel - slot.MinLevel + 1)
+		}
+
+		return Encounter{
+			Pokemon: slot.Pokemon,
+			Level:   level,
+			Method:  slot.Method,
+			Version: slot.Version,
+		}, true
+	}
+
+	return Encounter{}, false
+}
@@ -0,0 +1,153 @@
+package pokedex_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	pokeapi "github.com/nicholasss/pokedexcli/internal/pokeapi"
+	pokedex "github.com/nicholasss/pokedexcli/internal/pokedex"
+)
+
+// returns an area with grass in two versions and water in one
+func encounterArea(t *testing.T) pokeapi.LocationInfo {
+	data := `{
+		"name": "route-1-area",
+		"pokemon_encounters": [
+			{
+				"pokemon": {"name": "pidgey"},
+				"version_details": [
+					{
+						"version": {"name": "red", "url": "https://pokeapi.co/api/v2/version/1/"},
+						"encounter_details": [
+							{"chance": 35, "min_level": 2, "max_level": 5, "method": {"name": "walk"}}
+						]
+					},
+					{
+						"version": {"name": "yellow", "url": "https://pokeapi.co/api/v2/version/3/"},
+						"encounter_details": [
+							{"chance": 50, "min_level": 3, "max_level": 3, "method": {"name": "walk"}}
+						]
+					}
+				]
+			},
+			{
+				"pokemon": {"name": "rattata"},
+				"version_details": [
+					{
+						"version": {"name": "red", "url": "https://pokeapi.co/api/v2/version/1/"},
+						"encounter_details": [
+							{"chance": 65, "min_level": 2, "max_level": 4, "method": {"name": "walk"}}
+						]
+					},
+					{
+						"version": {"name": "yellow", "url": "https://pokeapi.co/api/v2/version/3/"},
+						"encounter_details": [
+							{"chance": 50, "min_level": 4, "max_level": 4, "method": {"name": "walk"}}
+						]
+					}
+				]
+			},
+			{
+				"pokemon": {"name": "poliwag"},
+				"version_details": [
+					{
+						"version": {"name": "red", "url": "https://pokeapi.co/api/v2/version/1/"},
+						"encounter_details": [
+							{"chance": 100, "min_level": 5, "max_level": 15, "method": {"name": "old-rod"}}
+						]
+					}
+				]
+			}
+		]
+	}`
+
+	var area pokeapi.LocationInfo
+	if err := json.Unmarshal([]byte(data), &area); err != nil {
+		t.Fatalf("unable to unmarshal area: %s", err)
+	}
+	return area
+}
+
+func TestEncounterSlots(t *testing.T) {
+	area := encounterArea(t)
+
+	cases := []struct {
+		method   string
+		version  string
+		expected string
+	}{
+		// the newest version is used when none is given
+		{method: "grass", expected: "yellow walk pidgey 3-3 50%, yellow walk rattata 4-4 50%"},
+		{method: "walk", version: "red", expected: "red walk pidgey 2-5 35%, red walk rattata 2-4 65%"},
+		{method: "fishing", expected: "red old-rod poliwag 5-15 100%"},
+		{method: "surf", expected: ""},
+		{method: "walk", version: "blue", expected: ""},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			var actual []string
+			for _, slot := range pokedex.EncounterSlots(area, pokedex.ParseMethod(c.method), c.version) {
+				actual = append(actual, fmt.Sprintf("%s %s %s %d-%d %d%%",
+					slot.Version, slot.Method, slot.Pokemon, slot.MinLevel, slot.MaxLevel, slot.Chance))
+			}
+
+			if strings.Join(actual, ", ") != c.expected {
+				t.Errorf("expected slots '%s', got '%s'", c.expected, strings.Join(actual, ", "))
+				return
+			}
+		})
+	}
+
+	methods := strings.Join(pokedex.EncounterMethods(area), ", ")
+	if methods != "old-rod, walk" {
+		t.Errorf("expected methods 'old-rod, walk', got '%s'", methods)
+	}
+}
+
+func TestRollEncounter(t *testing.T) {
+	slots := pokedex.EncounterSlots(encounterArea(t), "walk", "red")
+
+	cases := []struct {
+		seed     int64
+		expected []string
+	}{
+		{seed: 1, expected: []string{"rattata 2", "rattata 4", "rattata 2"}},
+		{seed: 2, expected: []string{"rattata 2", "rattata 4", "pidgey 4"}},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			source, _ := testSource(c.seed)
+			for j, expected := range c.expected {
+				encounter, found := pokedex.RollEncounter(source, slots)
+				actual := fmt.Sprintf("%s %d", encounter.Pokemon, encounter.Level)
+				if !found || actual != expected {
+					t.Errorf("expected encounter %d to be %s, got %s", j, expected, actual)
+					return
+				}
+			}
+		})
+	}
+
+	// the chances decide how often each pokemon appears
+	source, _ := testSource(3)
+	counts := make(map[string]int)
+	for range 10000 {
+		encounter, _ := pokedex.RollEncounter(source, slots)
+		counts[encounter.Pokemon]++
+		if encounter.Level < 2 || encounter.Level > 5 {
+			t.Errorf("expected a level from 2 to 5, got %d", encounter.Level)
+			return
+		}
+	}
+	if counts["rattata"] < 6000 || counts["rattata"] > 7000 {
+		t.Errorf("expected rattata about 65%% of the time, got %d in 10000", counts["rattata"])
+	}
+
+	if _, found := pokedex.RollEncounter(source, nil); found {
+		t.Errorf("expected no encounter without slots")
+	}
+}
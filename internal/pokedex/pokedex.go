@@ -1,84 +1,510 @@
 package pokedex
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
-	"math/rand"
+	"slices"
+	"sort"
 	"sync"
 	"time"
 
 	pokeapi "github.com/nicholasss/pokedexcli/internal/pokeapi"
 )
 
+// the genders a caught pokemon can have
+const (
+	Male       = "male"
+	Female     = "female"
+	Genderless = "genderless"
+)
+
 type Pokedex struct {
-	entries map[string]pokeapi.PokemonInfo
-	mux     sync.Mutex
+	// the data of every species caught, requested once for all of its individuals
+	species map[string]pokeapi.PokemonInfo
+	// every individual caught, by its ID
+	caught map[string]CaughtPokemon
+	// every pokemon encountered, whether or not it was caught
+	seen map[string]bool
+	// a planned team, the Pokemon do not need to be caught
+	team []TeamMember
+	// the items of the trainer and how many of each
+	bag map[string]int
+	// spent at the shop, earned by catching and exploring
+	money int
+	// every location area explored
+	explored map[string]bool
+	// the location area the trainer is in
+	location string
+	// the IDs of the caught pokemon in the party, and in each box of the PC
+	party []string
+	boxes [][]string
+	// where catch times come from
+	source *Source
+	mux    sync.Mutex
+	// counts every change, compared with the count when last saved
+	version      int
+	savedVersion int
+}
+
+// A single caught pokemon, the data of its species is kept once in the pokedex.
+// the level is zero and the location area empty when they are not known
+type CaughtPokemon struct {
+	ID           string    `json:"id"`
+	Species      string    `json:"species"`
+	Nickname     string    `json:"nickname,omitempty"`
+	CaughtAt     time.Time `json:"caught_at"`
+	LocationArea string    `json:"location_area,omitempty"`
+	Ball         string    `json:"ball,omitempty"`
+	Level        int       `json:"level,omitempty"`
+	Gender       string    `json:"gender,omitempty"`
+	// added from a file with the import command rather than caught,
+	// CaughtAt is then the time it was imported
+	Imported bool `json:"imported,omitempty"`
+}
+
+// reports whether two caught pokemon hold the same data.
+// times are compared by the instant, as a saved time loses its location and clock reading
+func (c CaughtPokemon) Equal(other CaughtPokemon) bool {
+	sameTime := c.CaughtAt.Equal(other.CaughtAt)
+	c.CaughtAt = time.Time{}
+	other.CaughtAt = time.Time{}
+
+	return sameTime && c == other
+}
+
+// returns the names of the fields that differ between two caught pokemon
+func (c CaughtPokemon) Changes(other CaughtPokemon) []string {
+	var changes []string
+	if c.Species != other.Species {
+		changes = append(changes, "species")
+	}
+	if c.Nickname != other.Nickname {
+		changes = append(changes, "nickname")
+	}
+	if !c.CaughtAt.Equal(other.CaughtAt) {
+		changes = append(changes, "catch time")
+	}
+	if c.LocationArea != other.LocationArea {
+		changes = append(changes, "location")
+	}
+	if c.Ball != other.Ball {
+		changes = append(changes, "ball")
+	}
+	if c.Level != other.Level {
+		changes = append(changes, "level")
+	}
+	if c.Gender != other.Gender {
+		changes = append(changes, "gender")
+	}
+	if c.Imported != other.Imported {
+		changes = append(changes, "imported")
+	}
+
+	return changes
+}
+
+// returns the nickname, or the species when there is none
+func (c CaughtPokemon) DisplayName() string {
+	if c.Nickname != "" {
+		return c.Nickname
+	}
+	return c.Species
 }
 
-func chanceCatch(baseXP int) bool {
-	// artificial limits on the potential min and max baseXP value
-	var max float32 = 800.0
-	var min float32 = 20.0
+// The same caught pokemon with different data in the pokedex and elsewhere.
+type Conflict struct {
+	ID        string
+	InPokedex CaughtPokemon
+	InRecords CaughtPokemon
+}
 
-	// min-max normalization formula
-	var probability float32 = 1.0 - ((float32(baseXP) - min) / (max - min))
+// What differs between the pokedex and a list of caught pokemon.
+type Difference struct {
+	OnlyInPokedex []CaughtPokemon
+	OnlyInRecords []CaughtPokemon
+	Conflicts     []Conflict
+}
 
-	// the higher the xp the more likely to return true
-	return rand.Float32() <= probability
+// reports whether there is no difference at all
+func (d Difference) Empty() bool {
+	return len(d.OnlyInPokedex) == 0 && len(d.OnlyInRecords) == 0 && len(d.Conflicts) == 0
+}
+
+// picks a gender from the gender rate of a species,
+// which is the chance of being female in eighths, or -1 for genderless
+func RollGender(source *Source, genderRate int) string {
+	switch {
+	case genderRate < 0:
+		return Genderless
+	case source.Intn(8) < genderRate:
+		return Female
+	}
+
+	return Male
 }
 
 func NewPokedex() *Pokedex {
-	return &Pokedex{
-		entries: make(map[string]pokeapi.PokemonInfo),
-		mux:     sync.Mutex{},
+	dex := &Pokedex{
+		species:  make(map[string]pokeapi.PokemonInfo),
+		caught:   make(map[string]CaughtPokemon),
+		seen:     make(map[string]bool),
+		bag:      make(map[string]int),
+		explored: make(map[string]bool),
+		money:    StarterMoney,
+		source:   NewRandomSource(),
+		mux:      sync.Mutex{},
+	}
+
+	// a new trainer is not an unsaved change
+	for _, item := range StarterBag() {
+		dex.bag[item.Name] = item.Quantity
+	}
+
+	return dex
+}
+
+// changes where the pokedex takes its catch times from
+func (p *Pokedex) SetSource(source *Source) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	p.source = source
+}
+
+// returns an ID that no caught pokemon has yet, expects the lock to be held.
+// IDs are 64 bits from crypto/rand rather than the source, so that they differ between saves
+func (p *Pokedex) newID() string {
+	for {
+		buf := make([]byte, 8)
+		if _, err := rand.Read(buf); err != nil {
+			panic(fmt.Sprintf("unable to read random bytes: %s", err))
+		}
+
+		ID := hex.EncodeToString(buf)
+		if _, exists := p.caught[ID]; !exists {
+			return ID
+		}
+	}
+}
+
+// reports whether two caught pokemon with the same ID are the same catch,
+// rather than different pokemon that were given the same ID in different saves
+func sameCatch(c, other CaughtPokemon) bool {
+	return c.Species == other.Species && c.CaughtAt.Equal(other.CaughtAt)
+}
+
+// adds a caught pokemon to the pokedex for finding later.
+// the ID, species and catch time are filled in, and the filled in record is returned
+func (p *Pokedex) Add(pokemonStruct pokeapi.PokemonInfo, caught CaughtPokemon) CaughtPokemon {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	caught.ID = p.newID()
+	caught.Species = pokemonStruct.Name
+	if caught.CaughtAt.IsZero() {
+		caught.CaughtAt = p.source.Now()
 	}
+
+	p.species[pokemonStruct.Name] = pokemonStruct
+	p.caught[caught.ID] = caught
+	p.route(caught.ID)
+	p.version++
+
+	return caught
 }
 
-// adds pokemon to the pokedex for finding later
-func (p *Pokedex) Add(name string, pokemonStruct pokeapi.PokemonInfo) {
+// adds pokemon marked as imported rather than caught.
+// a species already in the pokedex is not added again,
+// the names of those are returned
+func (p *Pokedex) Import(pokemon []pokeapi.PokemonInfo) []string {
 	p.mux.Lock()
 	defer p.mux.Unlock()
 
-	p.entries[name] = pokemonStruct
-	return
+	skipped := []string{}
+	importedAt := p.source.Now()
+	for _, pokemonStruct := range pokemon {
+		if _, exists := p.species[pokemonStruct.Name]; exists {
+			skipped = append(skipped, pokemonStruct.Name)
+			continue
+		}
+
+		ID := p.newID()
+		p.species[pokemonStruct.Name] = pokemonStruct
+		p.caught[ID] = CaughtPokemon{
+			ID:       ID,
+			Species:  pokemonStruct.Name,
+			CaughtAt: importedAt,
+			Imported: true,
+		}
+		p.route(ID)
+		p.version++
+	}
+
+	return skipped
 }
 
-// adds list of pokemon to the pokedex, for loading from save
-func (p *Pokedex) AddList(nameList []string) bool {
+// replaces the data of a species that is already in the pokedex,
+// such as after requesting it again
+func (p *Pokedex) UpdateSpecies(pokemonStruct pokeapi.PokemonInfo) {
 	p.mux.Lock()
 	defer p.mux.Unlock()
 
-	for _, name := range nameList {
-		nameURL := pokeapi.PokemonInfoURL + name
+	if _, exists := p.species[pokemonStruct.Name]; !exists {
+		return
+	}
+
+	p.species[pokemonStruct.Name] = pokemonStruct
+	p.version++
+}
+
+// gives a caught pokemon a nickname, an empty nickname removes it
+func (p *Pokedex) SetNickname(ID, nickname string) bool {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	caught, exists := p.caught[ID]
+	if !exists {
+		return false
+	}
+
+	caught.Nickname = nickname
+	p.caught[ID] = caught
+	p.version++
+
+	return true
+}
+
+// requests the data of every species that only has a name,
+// for loading from saves that only have the names
+func CompleteSpecies(species []pokeapi.PokemonInfo) bool {
+	for i, pokemonStruct := range species {
+		if pokemonStruct.ID != 0 {
+			continue
+		}
+
+		nameURL := pokeapi.PokemonInfoURL + pokemonStruct.Name
 		pokemonData, err := pokeapi.RequestGETBody(nameURL)
 		if err != nil {
 			fmt.Println("unable to request data when loading:", err)
 			return false
 		}
 
-		pokemonStruct, err := pokeapi.UnmarshalPokemonInfo(pokemonData)
+		completed, err := pokeapi.UnmarshalPokemonInfo(pokemonData)
 		if err != nil {
 			fmt.Println("unable to unmarshal data when loading:", err)
 			return false
 		}
-		p.entries[name] = pokemonStruct
+		species[i] = completed
 	}
 
 	return true
 }
 
+// replaces everything in the pokedex with the species and caught pokemon, for loading from save
+func (p *Pokedex) Replace(species []pokeapi.PokemonInfo, caught []CaughtPokemon) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	p.species = make(map[string]pokeapi.PokemonInfo)
+	for _, pokemonStruct := range species {
+		p.species[pokemonStruct.Name] = pokemonStruct
+	}
+
+	p.caught = make(map[string]CaughtPokemon)
+	for _, individual := range caught {
+		p.caught[individual.ID] = individual
+	}
+
+	// every pokemon is put away again, the layout can be replaced after
+	p.party = nil
+	p.boxes = nil
+	p.arrange()
+	p.version++
+}
+
+// adds the caught pokemon and species that are not in the pokedex yet.
+// caught pokemon that are already in the pokedex but differ from it are
+// returned as conflicts, and the pokedex keeps its own data.
+// a different pokemon that has the ID of one in the pokedex is added with a new ID
+func (p *Pokedex) Merge(species []pokeapi.PokemonInfo, caught []CaughtPokemon) []Conflict {
+	difference := p.Diff(caught)
+
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	for _, pokemonStruct := range species {
+		if _, exists := p.species[pokemonStruct.Name]; !exists {
+			p.species[pokemonStruct.Name] = pokemonStruct
+			p.version++
+		}
+	}
+
+	for _, individual := range caught {
+		current, exists := p.caught[individual.ID]
+		if exists && sameCatch(current, individual) {
+			continue
+		}
+		if exists {
+			individual.ID = p.newID()
+		}
+
+		p.caught[individual.ID] = individual
+		p.version++
+	}
+	p.arrange()
+
+	return difference.Conflicts
+}
+
+// compares the pokedex with caught pokemon, such as those in a save
+func (p *Pokedex) Diff(caught []CaughtPokemon) Difference {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	difference := Difference{
+		OnlyInPokedex: []CaughtPokemon{},
+		OnlyInRecords: []CaughtPokemon{},
+		Conflicts:     []Conflict{},
+	}
+
+	inRecords := make(map[string]bool)
+	for _, individual := range caught {
+		// a different pokemon with the same ID is in both, rather than a conflict
+		current, exists := p.caught[individual.ID]
+		if !exists || !sameCatch(current, individual) {
+			difference.OnlyInRecords = append(difference.OnlyInRecords, individual)
+			continue
+		}
+		inRecords[individual.ID] = true
+
+		if !current.Equal(individual) {
+			difference.Conflicts = append(difference.Conflicts, Conflict{
+				ID:        individual.ID,
+				InPokedex: current,
+				InRecords: individual,
+			})
+		}
+	}
+
+	for ID, individual := range p.caught {
+		if !inRecords[ID] {
+			difference.OnlyInPokedex = append(difference.OnlyInPokedex, individual)
+		}
+	}
+
+	sortCaught(difference.OnlyInPokedex)
+	sortCaught(difference.OnlyInRecords)
+	sort.Slice(difference.Conflicts, func(i, j int) bool {
+		return difference.Conflicts[i].ID < difference.Conflicts[j].ID
+	})
+
+	return difference
+}
+
 // finds pokemon in the Pokedex struct and if found returns the info struct
 func (p *Pokedex) Get(name string) (pokeapi.PokemonInfo, bool) {
 	p.mux.Lock()
 	defer p.mux.Unlock()
 
-	pokemon, ok := p.entries[name]
-	if !ok {
+	pokemonStruct, ok := p.species[name]
+	if !ok || !p.hasCaught(name) {
 		fmt.Printf("%s is not in the Pokedex.\nYou need to catch them first!\n", name)
 		return pokeapi.PokemonInfo{}, false
 	}
 
 	// fmt.Printf("%s was found in the Pokedex.\n", name)
-	return pokemon, true
+	return pokemonStruct, true
+}
+
+// reports whether any individual of the species was caught, expects the lock to be held
+func (p *Pokedex) hasCaught(name string) bool {
+	for _, individual := range p.caught {
+		if individual.Species == name {
+			return true
+		}
+	}
+	return false
+}
+
+// finds a caught pokemon by its ID
+func (p *Pokedex) Find(ID string) (CaughtPokemon, bool) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	individual, ok := p.caught[ID]
+	return individual, ok
+}
+
+// returns every caught pokemon of the species, oldest catch first
+func (p *Pokedex) Individuals(name string) []CaughtPokemon {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	individuals := []CaughtPokemon{}
+	for _, individual := range p.caught {
+		if individual.Species == name {
+			individuals = append(individuals, individual)
+		}
+	}
+	sortCaught(individuals)
+
+	return individuals
+}
+
+// returns every caught pokemon sorted by species and then by when it was caught,
+// this is used for saving
+func (p *Pokedex) Caught() []CaughtPokemon {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	return p.caughtList()
+}
+
+// expects the lock to be held
+func (p *Pokedex) caughtList() []CaughtPokemon {
+	caught := make([]CaughtPokemon, 0, len(p.caught))
+	for _, individual := range p.caught {
+		caught = append(caught, individual)
+	}
+	sortCaught(caught)
+
+	return caught
+}
+
+// returns the data of every species in the pokedex sorted by name,
+// this is used for saving
+func (p *Pokedex) Species() []pokeapi.PokemonInfo {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	return p.speciesList()
+}
+
+// expects the lock to be held
+func (p *Pokedex) speciesList() []pokeapi.PokemonInfo {
+	species := make([]pokeapi.PokemonInfo, 0, len(p.species))
+	for _, pokemonStruct := range p.species {
+		species = append(species, pokemonStruct)
+	}
+
+	sort.Slice(species, func(i, j int) bool {
+		return species[i].Name < species[j].Name
+	})
+
+	return species
+}
+
+func sortCaught(caught []CaughtPokemon) {
+	sort.Slice(caught, func(i, j int) bool {
+		if caught[i].Species != caught[j].Species {
+			return caught[i].Species < caught[j].Species
+		}
+		if !caught[i].CaughtAt.Equal(caught[j].CaughtAt) {
+			return caught[i].CaughtAt.Before(caught[j].CaughtAt)
+		}
+		return caught[i].ID < caught[j].ID
+	})
 }
 
 // returns a list of all Pokemon in the Pokemon entries
@@ -87,32 +513,200 @@ func (p *Pokedex) GetAll() ([]string, bool) {
 	p.mux.Lock()
 	defer p.mux.Unlock()
 
-	if len(p.entries) <= 0 {
+	if len(p.caught) <= 0 {
 		return []string{}, false
 	}
 
 	var names []string
-	for pokemon := range p.entries {
-		names = append(names, pokemon)
+	for _, individual := range p.caught {
+		if !slices.Contains(names, individual.Species) {
+			names = append(names, individual.Species)
+		}
 	}
 
 	return names, true
 }
 
-// will perform an attempt to 'catch' a pokemon and
-// add to the pokedex
-func AttemptCatch(pokemon pokeapi.PokemonInfo) bool {
-	baseXP := pokemon.BaseExperience
-	wasCaught := chanceCatch(baseXP)
+// records pokemon that were encountered, such as when exploring.
+// only pokemon that were not seen before change the pokedex, the count of those is returned
+func (p *Pokedex) MarkSeen(names ...string) int {
+	p.mux.Lock()
+	defer p.mux.Unlock()
 
-	fmt.Printf("Throwing a Pokeball at %s...\n", pokemon.Name)
-	time.Sleep(time.Second)
+	added := 0
+	for _, name := range names {
+		if name == "" || p.seen[name] {
+			continue
+		}
 
-	if wasCaught {
-		fmt.Printf("%s was caught!\n", pokemon.Name)
-		return true
+		p.seen[name] = true
+		added++
 	}
 
-	fmt.Printf("%s escaped!\n", pokemon.Name)
-	return false
+	if added > 0 {
+		p.version++
+	}
+
+	return added
+}
+
+// replaces every seen pokemon, for loading from save
+func (p *Pokedex) ReplaceSeen(names []string) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	p.seen = make(map[string]bool)
+	for _, name := range names {
+		p.seen[name] = true
+	}
+	p.version++
+}
+
+// returns every pokemon seen sorted by name, caught pokemon count as seen
+func (p *Pokedex) Seen() []string {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	return p.seenList()
+}
+
+// expects the lock to be held
+func (p *Pokedex) seenList() []string {
+	seen := make(map[string]bool)
+	for name := range p.seen {
+		seen[name] = true
+	}
+	for _, individual := range p.caught {
+		seen[individual.Species] = true
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// How much of a list of species was seen and caught.
+type Progress struct {
+	Name   string
+	Total  int
+	Seen   int
+	Caught int
+}
+
+// returns the share of the species caught as a percentage
+func (pr Progress) Completion() float64 {
+	if pr.Total == 0 {
+		return 0
+	}
+	return float64(pr.Caught) / float64(pr.Total) * 100
+}
+
+// counts how many of the species were seen and caught.
+// pokemon such as "deoxys-normal" count towards their species when it is known,
+// forms gives the species of those that were only seen, as their data is not in the pokedex
+func (p *Pokedex) Progress(name string, species []string, forms map[string]string) Progress {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	caught := make(map[string]bool)
+	for _, individual := range p.caught {
+		caught[p.speciesOf(individual.Species)] = true
+	}
+
+	seen := make(map[string]bool)
+	for pokemon := range p.seen {
+		if speciesName, ok := forms[pokemon]; ok {
+			seen[speciesName] = true
+			continue
+		}
+		seen[p.speciesOf(pokemon)] = true
+	}
+
+	progress := Progress{Name: name, Total: len(species)}
+	for _, speciesName := range species {
+		if caught[speciesName] {
+			progress.Caught++
+		}
+		if caught[speciesName] || seen[speciesName] {
+			progress.Seen++
+		}
+	}
+
+	return progress
+}
+
+// returns the species of a pokemon, expects the lock to be held.
+// the name is assumed to be the species when its data is not in the pokedex
+func (p *Pokedex) speciesOf(name string) string {
+	if pokemonStruct, ok := p.species[name]; ok && pokemonStruct.Species.Name != "" {
+		return pokemonStruct.Species.Name
+	}
+	return name
+}
+
+// Everything in the pokedex at one moment, for saving.
+type Snapshot struct {
+	// pass to MarkSaved once the snapshot is saved
+	Version  int
+	Species  []pokeapi.PokemonInfo
+	Caught   []CaughtPokemon
+	Seen     []string
+	Team     []TeamMember
+	Bag      []Item
+	Money    int
+	Explored []string
+	Location string
+	Party    []string
+	Boxes    [][]string
+}
+
+// returns a copy of the whole pokedex taken under one lock,
+// so that a change made while saving is not half in the save
+func (p *Pokedex) Snapshot() Snapshot {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	party, boxes := p.layout()
+	return Snapshot{
+		Version:  p.version,
+		Species:  p.speciesList(),
+		Caught:   p.caughtList(),
+		Seen:     p.seenList(),
+		Team:     cloneTeam(p.team),
+		Bag:      p.bagItems(),
+		Money:    p.money,
+		Explored: p.exploredList(),
+		Location: p.location,
+		Party:    party,
+		Boxes:    boxes,
+	}
+}
+
+// returns a count that changes every time the pokedex does,
+// read it before saving and pass it to MarkSaved afterwards
+func (p *Pokedex) Version() int {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	return p.version
+}
+
+// records that the pokedex was saved or loaded when it was at the version
+func (p *Pokedex) MarkSaved(version int) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	p.savedVersion = version
+}
+
+// reports whether there are changes since the pokedex was last saved or loaded
+func (p *Pokedex) IsDirty() bool {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	return p.version != p.savedVersion
 }
@@ -0,0 +1,384 @@
+package pokedex_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	pokeapi "github.com/nicholasss/pokedexcli/internal/pokeapi"
+	pokedex "github.com/nicholasss/pokedexcli/internal/pokedex"
+)
+
+func TestDirtyTracking(t *testing.T) {
+	dex := pokedex.NewPokedex()
+	if dex.IsDirty() {
+		t.Errorf("expected a new pokedex to have no unsaved changes")
+		return
+	}
+
+	dex.Add(pokeapi.PokemonInfo{ID: 25, Name: "pikachu"}, pokedex.CaughtPokemon{})
+	if !dex.IsDirty() {
+		t.Errorf("expected a catch to be an unsaved change")
+		return
+	}
+
+	// a change made while saving keeps the pokedex dirty
+	version := dex.Version()
+	dex.Add(pokeapi.PokemonInfo{ID: 133, Name: "eevee"}, pokedex.CaughtPokemon{})
+	dex.MarkSaved(version)
+	if !dex.IsDirty() {
+		t.Errorf("expected a change during a save to be unsaved")
+		return
+	}
+
+	dex.MarkSaved(dex.Version())
+	if dex.IsDirty() {
+		t.Errorf("expected no unsaved changes after saving")
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	dex := pokedex.NewPokedex()
+	caught := dex.Add(pokeapi.PokemonInfo{ID: 25, Name: "pikachu"}, pokedex.CaughtPokemon{})
+	dex.MarkSeen("eevee")
+	dex.AddItem(string(pokedex.GreatBall), 2)
+	dex.Earn(100)
+	dex.MarkExplored("viridian-forest-area")
+	dex.SetLocation("viridian-forest-area")
+
+	snapshot := dex.Snapshot()
+	if snapshot.Version != dex.Version() {
+		t.Errorf("expected version %d, got %d", dex.Version(), snapshot.Version)
+		return
+	}
+	if fmt.Sprint(snapshot.Caught) != fmt.Sprint(dex.Caught()) || fmt.Sprint(snapshot.Seen) != fmt.Sprint(dex.Seen()) {
+		t.Errorf("expected the snapshot to hold the caught and seen pokemon, got %+v", snapshot)
+		return
+	}
+	if fmt.Sprint(snapshot.Bag) != fmt.Sprint(dex.Bag()) || snapshot.Money != dex.Money() {
+		t.Errorf("expected the snapshot to hold the bag and money, got %+v", snapshot)
+		return
+	}
+	if fmt.Sprint(snapshot.Explored) != fmt.Sprint(dex.Explored()) || snapshot.Location != dex.Location() {
+		t.Errorf("expected the snapshot to hold the explored areas and location, got %+v", snapshot)
+		return
+	}
+	if len(snapshot.Party) != 1 || snapshot.Party[0] != caught.ID {
+		t.Errorf("expected %s in the party, got %v", caught.ID, snapshot.Party)
+		return
+	}
+
+	// the snapshot is a copy, later changes are not in it
+	dex.Earn(100)
+	if snapshot.Money == dex.Money() {
+		t.Errorf("expected the snapshot not to change with the pokedex")
+	}
+}
+
+func TestMergeAndDiff(t *testing.T) {
+	caughtAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	species := []pokeapi.PokemonInfo{
+		{ID: 25, Name: "pikachu"},
+		{ID: 1, Name: "bulbasaur"},
+		{ID: 4, Name: "charmander"},
+		{ID: 7, Name: "squirtle"},
+	}
+
+	dex := pokedex.NewPokedex()
+	dex.Replace(species[:3], []pokedex.CaughtPokemon{
+		{ID: "000001", Species: "pikachu", CaughtAt: caughtAt},
+		{ID: "000002", Species: "bulbasaur", CaughtAt: caughtAt},
+		{ID: "000003", Species: "charmander", CaughtAt: caughtAt},
+	})
+
+	saved := []pokedex.CaughtPokemon{
+		// the same instant in another location is not a conflict
+		{ID: "000001", Species: "pikachu", CaughtAt: caughtAt.In(time.FixedZone("test", 3600))},
+		{ID: "000002", Species: "bulbasaur", CaughtAt: caughtAt, Nickname: "Bulby"},
+		{ID: "000004", Species: "squirtle", CaughtAt: caughtAt},
+	}
+
+	difference := dex.Diff(saved)
+	if len(difference.OnlyInPokedex) != 1 || difference.OnlyInPokedex[0].Species != "charmander" {
+		t.Errorf("expected only charmander in the pokedex, got %v", difference.OnlyInPokedex)
+	}
+	if len(difference.OnlyInRecords) != 1 || difference.OnlyInRecords[0].Species != "squirtle" {
+		t.Errorf("expected only squirtle in the records, got %v", difference.OnlyInRecords)
+	}
+	if len(difference.Conflicts) != 1 || difference.Conflicts[0].ID != "000002" {
+		t.Errorf("expected a conflict for bulbasaur, got %v", difference.Conflicts)
+	}
+
+	conflicts := dex.Merge(species, saved)
+	if len(conflicts) != 1 {
+		t.Errorf("expected a single conflict when merging, got %d", len(conflicts))
+		return
+	}
+
+	if _, ok := dex.Get("squirtle"); !ok {
+		t.Errorf("expected squirtle to be merged in")
+	}
+
+	// the pokedex keeps its own data on conflicts
+	if bulbasaur, _ := dex.Find("000002"); bulbasaur.Nickname != "" {
+		t.Errorf("expected the pokedex data to be kept for bulbasaur")
+	}
+
+	// a different pokemon with an ID already in the pokedex is added with a new ID
+	clash := []pokedex.CaughtPokemon{{ID: "000003", Species: "squirtle", CaughtAt: caughtAt.Add(time.Hour)}}
+	difference = dex.Diff(clash)
+	if len(difference.Conflicts) != 0 || len(difference.OnlyInRecords) != 1 {
+		t.Errorf("expected the clash to be a different pokemon, got %+v", difference)
+	}
+	if conflicts := dex.Merge(species, clash); len(conflicts) != 0 {
+		t.Errorf("expected no conflicts for a clash, got %v", conflicts)
+	}
+	if len(dex.Individuals("squirtle")) != 2 {
+		t.Errorf("expected the clashing squirtle to be kept, got %v", dex.Individuals("squirtle"))
+	}
+	if charmander, _ := dex.Find("000003"); charmander.Species != "charmander" {
+		t.Errorf("expected charmander to keep its ID, got %s", charmander.Species)
+	}
+
+	dex.Replace(species, saved)
+	if len(dex.Caught()) != 3 {
+		t.Errorf("expected 3 caught pokemon after replacing, got %d", len(dex.Caught()))
+	}
+	if _, ok := dex.Get("charmander"); ok {
+		t.Errorf("expected charmander to be gone after replacing")
+	}
+}
+
+func TestIndividuals(t *testing.T) {
+	dex := pokedex.NewPokedex()
+	pikachu := pokeapi.PokemonInfo{ID: 25, Name: "pikachu"}
+
+	first := dex.Add(pikachu, pokedex.CaughtPokemon{Nickname: "Sparky", CaughtAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)})
+	second := dex.Add(pikachu, pokedex.CaughtPokemon{Gender: pokedex.Female, Ball: "great-ball"})
+
+	if first.ID == "" || first.ID == second.ID {
+		t.Errorf("expected each catch to have its own ID, got '%s' and '%s'", first.ID, second.ID)
+		return
+	}
+
+	individuals := dex.Individuals("pikachu")
+	if len(individuals) != 2 {
+		t.Errorf("expected both pikachu to be kept, got %d", len(individuals))
+		return
+	}
+	if individuals[0].DisplayName() != "Sparky" || individuals[1].Ball != "great-ball" {
+		t.Errorf("expected the oldest catch first, got %+v", individuals)
+	}
+
+	if len(dex.Species()) != 1 {
+		t.Errorf("expected the species data to be kept once, got %d", len(dex.Species()))
+	}
+
+	if !dex.SetNickname(second.ID, "Volt") {
+		t.Errorf("expected to nickname %s", second.ID)
+	}
+	if renamed, _ := dex.Find(second.ID); renamed.Nickname != "Volt" {
+		t.Errorf("expected nickname 'Volt', got '%s'", renamed.Nickname)
+	}
+}
+
+func TestImport(t *testing.T) {
+	dex := pokedex.NewPokedex()
+	dex.Add(pokeapi.PokemonInfo{ID: 25, Name: "pikachu"}, pokedex.CaughtPokemon{})
+
+	skipped := dex.Import([]pokeapi.PokemonInfo{
+		{ID: 25, Name: "pikachu"},
+		{ID: 1, Name: "bulbasaur"},
+	})
+	if len(skipped) != 1 || skipped[0] != "pikachu" {
+		t.Errorf("expected pikachu to be skipped, got %v", skipped)
+		return
+	}
+
+	for _, individual := range dex.Caught() {
+		expected := individual.Species == "bulbasaur"
+		if individual.Imported != expected {
+			t.Errorf("expected %s to have imported %v, got %v", individual.Species, expected, individual.Imported)
+		}
+	}
+}
+
+func TestProgress(t *testing.T) {
+	dex := pokedex.NewPokedex()
+	dex.Add(pokeapi.PokemonInfo{ID: 1, Name: "bulbasaur"}, pokedex.CaughtPokemon{})
+
+	// a form counts towards its species
+	deoxys := pokeapi.PokemonInfo{ID: 386, Name: "deoxys-normal"}
+	deoxys.Species.Name = "deoxys"
+	dex.Add(deoxys, pokedex.CaughtPokemon{})
+
+	if added := dex.MarkSeen("pidgey", "rattata", "bulbasaur", "basculin-red-striped"); added != 4 {
+		t.Errorf("expected 4 newly seen pokemon, got %d", added)
+	}
+	version := dex.Version()
+	if added := dex.MarkSeen("pidgey"); added != 0 || dex.Version() != version {
+		t.Errorf("expected seeing pidgey again to change nothing")
+	}
+
+	cases := []struct {
+		species        []string
+		expectedSeen   int
+		expectedCaught int
+	}{
+		{species: []string{"bulbasaur", "ivysaur", "pidgey", "rattata"}, expectedSeen: 3, expectedCaught: 1},
+		{species: []string{"deoxys", "jirachi"}, expectedSeen: 1, expectedCaught: 1},
+		{species: []string{}, expectedSeen: 0, expectedCaught: 0},
+		// a form that was only seen counts towards its species
+		{species: []string{"basculin"}, expectedSeen: 1, expectedCaught: 0},
+	}
+
+	forms := map[string]string{"basculin-red-striped": "basculin"}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			progress := dex.Progress("test", c.species, forms)
+			if progress.Seen != c.expectedSeen || progress.Caught != c.expectedCaught || progress.Total != len(c.species) {
+				t.Errorf("expected %d seen and %d caught of %d, got %+v", c.expectedSeen, c.expectedCaught, len(c.species), progress)
+			}
+		})
+	}
+
+	expectedSeen := "basculin-red-striped bulbasaur deoxys-normal pidgey rattata"
+	if seen := strings.Join(dex.Seen(), " "); seen != expectedSeen {
+		t.Errorf("expected seen '%s', got '%s'", expectedSeen, seen)
+	}
+}
+
+func TestSeededSource(t *testing.T) {
+	// two pokedexes with the same seed catch at the same times, but never share IDs
+	var catches [2][]pokedex.CaughtPokemon
+	for i := range catches {
+		source, clock := testSource(42)
+		dex := pokedex.NewPokedex()
+		dex.SetSource(source)
+
+		for _, name := range []string{"pikachu", "eevee", "pikachu"} {
+			caught := dex.Add(pokeapi.PokemonInfo{Name: name}, pokedex.CaughtPokemon{})
+			if !caught.CaughtAt.Equal(clock.at) {
+				t.Errorf("expected %s to be caught at %s, got %s", name, clock.at, caught.CaughtAt)
+				return
+			}
+			catches[i] = append(catches[i], caught)
+		}
+	}
+
+	for i := range catches[0] {
+		if catches[0][i].ID == catches[1][i].ID {
+			t.Errorf("expected catch %d to have different IDs, both got %s", i, catches[0][i].ID)
+			return
+		}
+	}
+}
+
+func TestBag(t *testing.T) {
+	dex := pokedex.NewPokedex()
+	if dex.IsDirty() {
+		t.Errorf("expected the starter bag not to be an unsaved change")
+		return
+	}
+
+	dex.AddItem("great-ball", 2)
+	dex.AddItem("revive", 1)
+	if err := dex.UseItem("potion", 3); err != nil {
+		t.Errorf("unable to use potions: %s", err)
+		return
+	}
+
+	// nothing is taken when there are not enough
+	if err := dex.UseItem("great-ball", 3); err == nil {
+		t.Errorf("expected an error using more great balls than there are")
+		return
+	}
+
+	var actual []string
+	for _, item := range dex.Bag() {
+		actual = append(actual, fmt.Sprintf("%s:%d", item.Name, item.Quantity))
+	}
+
+	// balls first, then medicine
+	expected := "great-ball:2 poke-ball:10 revive:1"
+	if strings.Join(actual, " ") != expected {
+		t.Errorf("expected bag %s, got %s", expected, strings.Join(actual, " "))
+		return
+	}
+
+	// the bag holds up to the most of one item a save can have
+	if added := dex.AddItem("poke-ball", pokedex.MaxQuantity); added != pokedex.MaxQuantity-10 {
+		t.Errorf("expected %d poke balls to fit in the bag, got %d", pokedex.MaxQuantity-10, added)
+		return
+	}
+	if dex.ItemCount("poke-ball") != pokedex.MaxQuantity {
+		t.Errorf("expected %d poke balls, got %d", pokedex.MaxQuantity, dex.ItemCount("poke-ball"))
+		return
+	}
+
+	if !dex.MarkExplored("viridian-forest-area") || dex.MarkExplored("viridian-forest-area") {
+		t.Errorf("expected an area to be new only the first time it is explored")
+	}
+}
+
+func TestLocation(t *testing.T) {
+	dex := pokedex.NewPokedex()
+	if dex.Location() != "" {
+		t.Errorf("expected a new trainer not to be anywhere, got %s", dex.Location())
+		return
+	}
+
+	dex.SetLocation("mt-moon-1f")
+	if dex.Location() != "mt-moon-1f" || !dex.IsDirty() {
+		t.Errorf("expected traveling to be an unsaved change")
+		return
+	}
+
+	// staying in the same area changes nothing
+	dex.MarkSaved(dex.Version())
+	dex.SetLocation("mt-moon-1f")
+	if dex.IsDirty() {
+		t.Errorf("expected traveling to the same area not to be a change")
+	}
+}
+
+func TestIsCave(t *testing.T) {
+	cases := []struct {
+		area     string
+		expected bool
+	}{
+		{area: "cerulean-cave-1f", expected: true},
+		{area: "rock-tunnel-b1f", expected: true},
+		{area: "wayward-cave-b1f", expected: true},
+		{area: "viridian-forest-area", expected: false},
+		// only whole words count
+		{area: "caveman-town-area", expected: false},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			if actual := pokedex.IsCave(c.area); actual != c.expected {
+				t.Errorf("expected %s to be a cave: %v, got %v", c.area, c.expected, actual)
+				return
+			}
+		})
+	}
+}
+
+func TestTeam(t *testing.T) {
+	dex := pokedex.NewPokedex()
+	team := []pokedex.TeamMember{{Species: "pikachu", Level: 50, Moves: []string{"volt-tackle"}}}
+	dex.SetTeam(team)
+
+	// changing the team afterwards does not change the pokedex
+	team[0].Moves[0] = "thunderbolt"
+	if moves := dex.Team()[0].Moves; moves[0] != "volt-tackle" {
+		t.Errorf("expected the team in the pokedex to keep volt-tackle, got %v", moves)
+	}
+	if !dex.IsDirty() {
+		t.Errorf("expected a new team to be an unsaved change")
+	}
+}
@@ -0,0 +1,185 @@
+package pokedex
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	pokeapi "github.com/nicholasss/pokedexcli/internal/pokeapi"
+)
+
+// How query results are ordered.
+type SortKey string
+
+const (
+	// by pokedex number, lowest first
+	SortID SortKey = "id"
+	// by name, alphabetically
+	SortName SortKey = "name"
+	// by base stat total, highest first
+	SortBST SortKey = "bst"
+	// by when the species was first caught, oldest first
+	SortCaughtAt SortKey = "caught-at"
+)
+
+// every sort key, in the order they are listed to the user
+var SortKeys = []SortKey{SortID, SortName, SortBST, SortCaughtAt}
+
+// the names of the stats that can be filtered on
+var StatNames = []string{"hp", "attack", "defense", "special-attack", "special-defense", "speed"}
+
+// Narrows down and orders the species in the pokedex.
+// empty fields do not filter anything
+type Query struct {
+	// every type listed must be one of the species' types
+	Types []string
+	// only these species are included, such as the species of a generation.
+	// nil includes every species, while an empty set includes none
+	Species map[string]bool
+	// the lowest base value of each stat, by stat name
+	MinStats map[string]int
+	Sort     SortKey
+	// the most results returned, zero returns every result
+	Limit int
+}
+
+// A species in the pokedex that matched a query.
+type Result struct {
+	Pokemon       pokeapi.PokemonInfo
+	BaseStatTotal int
+	// every individual of the species, oldest catch first
+	Caught []CaughtPokemon
+}
+
+// reports whether every individual of the species was imported rather than caught
+func (r Result) Imported() bool {
+	for _, individual := range r.Caught {
+		if !individual.Imported {
+			return false
+		}
+	}
+	return len(r.Caught) > 0
+}
+
+// checks the query for values that cannot match anything, such as unknown stats
+func (q Query) Validate() error {
+	if q.Sort != "" && !slices.Contains(SortKeys, q.Sort) {
+		keys := make([]string, 0, len(SortKeys))
+		for _, key := range SortKeys {
+			keys = append(keys, string(key))
+		}
+		return fmt.Errorf("unknown sort '%s', use one of: %s", q.Sort, strings.Join(keys, ", "))
+	}
+
+	for stat := range q.MinStats {
+		if !slices.Contains(StatNames, stat) {
+			return fmt.Errorf("unknown stat '%s', use one of: %s", stat, strings.Join(StatNames, ", "))
+		}
+	}
+
+	if q.Limit < 0 {
+		return fmt.Errorf("limit must not be negative")
+	}
+
+	return nil
+}
+
+// returns the caught species that match the query, in the order asked for
+func (p *Pokedex) Query(q Query) ([]Result, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	bySpecies := make(map[string][]CaughtPokemon)
+	for _, individual := range p.caught {
+		bySpecies[individual.Species] = append(bySpecies[individual.Species], individual)
+	}
+
+	results := []Result{}
+	for name, caught := range bySpecies {
+		pokemon := p.species[name]
+		if !q.matches(pokemon, p.speciesOf(name)) {
+			continue
+		}
+
+		sortCaught(caught)
+		results = append(results, Result{
+			Pokemon:       pokemon,
+			BaseStatTotal: pokeapi.BaseStatTotal(pokemon),
+			Caught:        caught,
+		})
+	}
+
+	sortResults(results, q.Sort)
+
+	if q.Limit > 0 && len(results) > q.Limit {
+		results = results[:q.Limit]
+	}
+
+	return results, nil
+}
+
+// reports whether the species passes every filter of the query
+func (q Query) matches(pokemon pokeapi.PokemonInfo, species string) bool {
+	if q.Species != nil && !q.Species[species] {
+		return false
+	}
+
+	var types []string
+	for _, pType := range pokemon.TypeList {
+		types = append(types, pType.PType.Name)
+	}
+	for _, wanted := range q.Types {
+		if !slices.Contains(types, wanted) {
+			return false
+		}
+	}
+
+	for stat, minimum := range q.MinStats {
+		if baseStat(pokemon, stat) < minimum {
+			return false
+		}
+	}
+
+	return true
+}
+
+func sortResults(results []Result, key SortKey) {
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+
+		switch key {
+		case SortName:
+			return a.Pokemon.Name < b.Pokemon.Name
+		case SortBST:
+			if a.BaseStatTotal != b.BaseStatTotal {
+				return a.BaseStatTotal > b.BaseStatTotal
+			}
+		case SortCaughtAt:
+			first, second := a.Caught[0].CaughtAt, b.Caught[0].CaughtAt
+			if !first.Equal(second) {
+				return first.Before(second)
+			}
+		}
+
+		// pokedex number is the default and breaks ties
+		if a.Pokemon.ID != b.Pokemon.ID {
+			return a.Pokemon.ID < b.Pokemon.ID
+		}
+		return a.Pokemon.Name < b.Pokemon.Name
+	})
+}
+
+// returns the base value of the stat, or zero when the pokemon does not have it
+func baseStat(pokemon pokeapi.PokemonInfo, name string) int {
+	for _, stat := range pokemon.StatList {
+		if stat.Stat.Name == name {
+			return stat.BaseStat
+		}
+	}
+	return 0
+}
@@ -0,0 +1,110 @@
+package pokedex_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	pokeapi "github.com/nicholasss/pokedexcli/internal/pokeapi"
+	pokedex "github.com/nicholasss/pokedexcli/internal/pokedex"
+)
+
+// returns a pokedex with four species caught a day apart, out of pokedex order
+func queryPokedex(t *testing.T) *pokedex.Pokedex {
+	data := []string{
+		`{"id": 6, "name": "charizard", "stats": [{"base_stat": 78, "stat": {"name": "hp"}}, {"base_stat": 84, "stat": {"name": "attack"}}, {"base_stat": 100, "stat": {"name": "speed"}}], "types": [{"type": {"name": "fire"}}, {"type": {"name": "flying"}}]}`,
+		`{"id": 4, "name": "charmander", "stats": [{"base_stat": 39, "stat": {"name": "hp"}}, {"base_stat": 52, "stat": {"name": "attack"}}, {"base_stat": 65, "stat": {"name": "speed"}}], "types": [{"type": {"name": "fire"}}]}`,
+		`{"id": 25, "name": "pikachu", "stats": [{"base_stat": 35, "stat": {"name": "hp"}}, {"base_stat": 55, "stat": {"name": "attack"}}, {"base_stat": 90, "stat": {"name": "speed"}}], "types": [{"type": {"name": "electric"}}]}`,
+		`{"id": 155, "name": "cyndaquil", "stats": [{"base_stat": 39, "stat": {"name": "hp"}}, {"base_stat": 52, "stat": {"name": "attack"}}, {"base_stat": 65, "stat": {"name": "speed"}}], "types": [{"type": {"name": "fire"}}]}`,
+	}
+
+	dex := pokedex.NewPokedex()
+	for i, pokemonData := range data {
+		var pokemon pokeapi.PokemonInfo
+		if err := json.Unmarshal([]byte(pokemonData), &pokemon); err != nil {
+			t.Fatalf("unable to unmarshal pokemon: %s", err)
+		}
+
+		dex.Add(pokemon, pokedex.CaughtPokemon{
+			CaughtAt: time.Date(2025, 1, 10-i, 0, 0, 0, 0, time.UTC),
+		})
+	}
+
+	return dex
+}
+
+func TestQuery(t *testing.T) {
+	dex := queryPokedex(t)
+
+	cases := []struct {
+		query    pokedex.Query
+		expected string
+	}{
+		{
+			query:    pokedex.Query{},
+			expected: "charmander charizard pikachu cyndaquil",
+		},
+		{
+			query:    pokedex.Query{Types: []string{"fire"}, Sort: pokedex.SortName},
+			expected: "charizard charmander cyndaquil",
+		},
+		{
+			query:    pokedex.Query{Types: []string{"fire", "flying"}},
+			expected: "charizard",
+		},
+		{
+			query:    pokedex.Query{Species: map[string]bool{"bulbasaur": true, "charmander": true, "charizard": true, "pikachu": true}, Sort: pokedex.SortBST},
+			expected: "charizard pikachu charmander",
+		},
+		// a generation without any of the species includes none
+		{
+			query:    pokedex.Query{Species: map[string]bool{}},
+			expected: "",
+		},
+		{
+			query:    pokedex.Query{MinStats: map[string]int{"speed": 90}},
+			expected: "charizard pikachu",
+		},
+		{
+			query:    pokedex.Query{Sort: pokedex.SortCaughtAt, Limit: 2},
+			expected: "cyndaquil pikachu",
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			results, err := dex.Query(c.query)
+			if err != nil {
+				t.Errorf("unable to query: %s", err)
+				return
+			}
+
+			var names []string
+			for _, result := range results {
+				names = append(names, result.Pokemon.Name)
+			}
+
+			if strings.Join(names, " ") != c.expected {
+				t.Errorf("expected '%s', got '%s'", c.expected, strings.Join(names, " "))
+			}
+		})
+	}
+}
+
+func TestQueryValidate(t *testing.T) {
+	cases := []pokedex.Query{
+		{Sort: "weight"},
+		{MinStats: map[string]int{"luck": 10}},
+		{Limit: -1},
+	}
+
+	for i, query := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			if _, err := queryPokedex(t).Query(query); err == nil {
+				t.Errorf("expected an error for %+v", query)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package pokedex
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// =========
+// Constants
+// =========
+
+// the money a new trainer starts with
+const StarterMoney = 3000
+
+// the money for exploring an area for the first time
+const ExploreReward = 200
+
+// the most money a trainer can have, as in the games
+const MaxMoney = 999999
+
+// every item the shop sells, in the order they are listed.
+// prices come from the PokeAPI, items it gives no price are not sold
+var ShopItems = []string{
+	string(PokeBall), string(GreatBall), string(UltraBall),
+	string(QuickBall), string(DuskBall), string(NetBall), string(RepeatBall),
+	Potion, SuperPotion, HyperPotion, Revive,
+}
+
+// ==============
+// Shop Functions
+// ==============
+
+// finds the item by a name such as "great", "great ball" or "super-potion"
+func ParseItem(name string) string {
+	slug := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "-")
+	if ball, err := ParseBall(slug); err == nil {
+		return string(ball)
+	}
+	return slug
+}
+
+// returns the money for catching a pokemon, harder catches are worth more
+func CatchReward(captureRate int) int {
+	return 100 + 2*(255-min(max(captureRate, 0), 255))
+}
+
+// returns what the shop pays for an item, half of what it costs
+func SellPrice(cost int) int {
+	return cost / 2
+}
+
+// returns the money of the trainer
+func (p *Pokedex) Money() int {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	return p.money
+}
+
+// gives the trainer money, up to the most they can have
+func (p *Pokedex) Earn(amount int) {
+	if amount <= 0 {
+		return
+	}
+
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	p.money = min(p.money+amount, MaxMoney)
+	p.version++
+}
+
+// replaces the money of the trainer, for loading from save
+func (p *Pokedex) SetMoney(amount int) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	p.money = min(max(amount, 0), MaxMoney)
+	p.version++
+}
+
+// pays for a number of the item and puts them in the bag,
+// nothing changes when there is not enough money or room in the bag
+func (p *Pokedex) Buy(name string, quantity, cost int) error {
+	if quantity <= 0 || quantity > MaxQuantity {
+		return fmt.Errorf("quantity must be from 1 to %d", MaxQuantity)
+	}
+	if cost <= 0 || !slices.Contains(ShopItems, name) {
+		return fmt.Errorf("the shop does not sell %s", ItemName(name))
+	}
+
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	if p.bag[name]+quantity > MaxQuantity {
+		return fmt.Errorf("the bag can only hold %d %s, you have %d", MaxQuantity, ItemName(name), p.bag[name])
+	}
+
+	total := cost * quantity
+	if total > p.money {
+		return fmt.Errorf("%d %s cost $%d, you have $%d", quantity, ItemName(name), total, p.money)
+	}
+
+	p.money -= total
+	p.bag[name] += quantity
+	p.version++
+
+	return nil
+}
+
+// sells a number of the item from the bag for half of what it costs,
+// nothing changes when there are not enough in the bag.
+// returns the money earned
+func (p *Pokedex) Sell(name string, quantity, cost int) (int, error) {
+	if quantity <= 0 {
+		return 0, fmt.Errorf("quantity must be at least 1")
+	}
+	if SellPrice(cost) <= 0 {
+		return 0, fmt.Errorf("%s cannot be sold", ItemName(name))
+	}
+
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	if p.bag[name] < quantity {
+		return 0, fmt.Errorf("not enough %s, %d left", ItemName(name), p.bag[name])
+	}
+
+	p.bag[name] -= quantity
+	if p.bag[name] == 0 {
+		delete(p.bag, name)
+	}
+
+	earned := SellPrice(cost) * quantity
+	p.money = min(p.money+earned, MaxMoney)
+	p.version++
+
+	return earned, nil
+}
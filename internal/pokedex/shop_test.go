@@ -0,0 +1,113 @@
+package pokedex_test
+
+import (
+	"fmt"
+	"testing"
+
+	pokedex "github.com/nicholasss/pokedexcli/internal/pokedex"
+)
+
+func TestBuyAndSell(t *testing.T) {
+	cases := []struct {
+		sell     bool
+		item     string
+		quantity int
+		cost     int
+		err      bool
+		money    int
+		inBag    int
+	}{
+		// a new trainer has $3000 and 10 poke balls
+		{item: "great-ball", quantity: 5, cost: 600, money: 0, inBag: 5},
+		{item: "great-ball", quantity: 1, cost: 600, err: true, money: 0, inBag: 5},
+		{sell: true, item: "great-ball", quantity: 2, cost: 600, money: 600, inBag: 3},
+		{sell: true, item: "poke-ball", quantity: 11, cost: 200, err: true, money: 600, inBag: 10},
+		{sell: true, item: "poke-ball", quantity: 10, cost: 200, money: 1600, inBag: 0},
+		{item: "potion", quantity: 2, cost: 300, money: 1000, inBag: 5},
+		// not sold at the shop, or without a price
+		{item: "master-ball", quantity: 1, cost: 0, err: true, money: 1000, inBag: 0},
+		{item: "rare-candy", quantity: 1, cost: 10000, err: true, money: 1000, inBag: 0},
+		{sell: true, item: "potion", quantity: 1, cost: 0, err: true, money: 1000, inBag: 5},
+		{item: "potion", quantity: 0, cost: 300, err: true, money: 1000, inBag: 5},
+		// more than the bag can hold, the total would overflow
+		{item: "poke-ball", quantity: 92233720368547758, cost: 200, err: true, money: 1000, inBag: 0},
+		{item: "potion", quantity: 995, cost: 1, err: true, money: 1000, inBag: 5},
+		{item: "potion", quantity: 994, cost: 1, money: 6, inBag: 999},
+	}
+
+	// each case continues from the last
+	dex := pokedex.NewPokedex()
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			var err error
+			if c.sell {
+				_, err = dex.Sell(c.item, c.quantity, c.cost)
+			} else {
+				err = dex.Buy(c.item, c.quantity, c.cost)
+			}
+
+			if (err != nil) != c.err {
+				t.Errorf("expected error to be %v, got %v", c.err, err)
+				return
+			}
+			if dex.Money() != c.money {
+				t.Errorf("expected $%d, got $%d", c.money, dex.Money())
+				return
+			}
+			if dex.ItemCount(c.item) != c.inBag {
+				t.Errorf("expected %d %s in the bag, got %d", c.inBag, c.item, dex.ItemCount(c.item))
+				return
+			}
+		})
+	}
+}
+
+func TestRewards(t *testing.T) {
+	cases := []struct {
+		captureRate int
+		expected    int
+	}{
+		{captureRate: 255, expected: 100},
+		{captureRate: 45, expected: 520},
+		{captureRate: 3, expected: 604},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			actual := pokedex.CatchReward(c.captureRate)
+			if actual != c.expected {
+				t.Errorf("expected $%d, got $%d", c.expected, actual)
+				return
+			}
+		})
+	}
+
+	dex := pokedex.NewPokedex()
+	dex.Earn(pokedex.MaxMoney)
+	if dex.Money() != pokedex.MaxMoney {
+		t.Errorf("expected money to stop at $%d, got $%d", pokedex.MaxMoney, dex.Money())
+	}
+}
+
+func TestParseItem(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{input: "great", expected: "great-ball"},
+		{input: "Great Ball", expected: "great-ball"},
+		{input: "super potion", expected: "super-potion"},
+		{input: "potion", expected: "potion"},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			actual := pokedex.ParseItem(c.input)
+			if actual != c.expected {
+				t.Errorf("expected %s, got %s", c.expected, actual)
+				return
+			}
+		})
+	}
+}
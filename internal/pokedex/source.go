@@ -0,0 +1,57 @@
+package pokedex
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Tells the time and waits, replaced in tests so that nothing waits for real.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+// The clock of the system.
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time        { return time.Now() }
+func (SystemClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+// Where the randomness and the time of catches and encounters come from.
+// two sources with the same seed and clock give the same outcomes
+type Source struct {
+	rng   *rand.Rand
+	clock Clock
+	// a rand.Rand is not safe to use from more than one goroutine
+	mux sync.Mutex
+}
+
+// returns a source of randomness seeded with the seed, using the clock
+func NewSource(seed int64, clock Clock) *Source {
+	return &Source{
+		rng:   rand.New(rand.NewSource(seed)),
+		clock: clock,
+	}
+}
+
+// returns a source seeded from the time, using the system clock
+func NewRandomSource() *Source {
+	return NewSource(time.Now().UnixNano(), SystemClock{})
+}
+
+// returns a random number from zero up to but not including n
+func (s *Source) Intn(n int) int {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return s.rng.Intn(n)
+}
+
+func (s *Source) Now() time.Time {
+	return s.clock.Now()
+}
+
+func (s *Source) Sleep(d time.Duration) {
+	s.clock.Sleep(d)
+}
@@ -0,0 +1,277 @@
+package pokedex
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// the most pokemon the party can hold
+const PartySize = 6
+
+// the most pokemon one box of the PC can hold
+const BoxSize = 30
+
+// Where a caught pokemon is kept, in the party or in a box of the PC.
+type Place struct {
+	// the number of the box starting at 1, or 0 for the party
+	Box int
+	// the position in the party or box, starting at 1
+	Slot int
+}
+
+// reports whether the place is in the party
+func (pl Place) InParty() bool {
+	return pl.Box == 0
+}
+
+// returns the caught pokemon in the party, in order
+func (p *Pokedex) Party() []CaughtPokemon {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	return p.individuals(p.party)
+}
+
+// returns the number of boxes in the PC, there is always at least one
+func (p *Pokedex) BoxCount() int {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	return max(len(p.boxes), 1)
+}
+
+// returns the caught pokemon in a box, numbered from 1, in order
+func (p *Pokedex) Box(number int) ([]CaughtPokemon, error) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	if number < 1 || number > max(len(p.boxes), 1) {
+		return nil, fmt.Errorf("there is no box %d, the PC has %d", number, max(len(p.boxes), 1))
+	}
+	if number > len(p.boxes) {
+		return []CaughtPokemon{}, nil
+	}
+
+	return p.individuals(p.boxes[number-1]), nil
+}
+
+// returns where a caught pokemon is kept
+func (p *Pokedex) PlaceOf(ID string) (Place, bool) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	return p.placeOf(ID)
+}
+
+// moves a pokemon from the party to the first box with space,
+// the party always keeps at least one pokemon.
+// returns the box it was put in
+func (p *Pokedex) Deposit(ID string) (int, error) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	place, found := p.placeOf(ID)
+	switch {
+	case !found:
+		return 0, fmt.Errorf("no caught pokemon has the ID '%s'", ID)
+	case !place.InParty():
+		return 0, fmt.Errorf("#%s is already in box %d", ID, place.Box)
+	case len(p.party) == 1:
+		return 0, fmt.Errorf("the party must keep at least one pokemon")
+	}
+
+	p.party = slices.Delete(p.party, place.Slot-1, place.Slot)
+	box := p.store(ID)
+	p.version++
+
+	return box, nil
+}
+
+// moves a pokemon from its box to the end of the party
+func (p *Pokedex) Withdraw(ID string) error {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	place, found := p.placeOf(ID)
+	switch {
+	case !found:
+		return fmt.Errorf("no caught pokemon has the ID '%s'", ID)
+	case place.InParty():
+		return fmt.Errorf("#%s is already in the party", ID)
+	case len(p.party) >= PartySize:
+		return fmt.Errorf("the party is full, deposit or swap a pokemon first")
+	}
+
+	box := place.Box - 1
+	p.boxes[box] = slices.Delete(p.boxes[box], place.Slot-1, place.Slot)
+	p.trimBoxes()
+	p.party = append(p.party, ID)
+	p.version++
+
+	return nil
+}
+
+// swaps the places of two caught pokemon, such as one in the party with one in a box
+func (p *Pokedex) Swap(firstID, secondID string) error {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	first, found := p.placeOf(firstID)
+	if !found {
+		return fmt.Errorf("no caught pokemon has the ID '%s'", firstID)
+	}
+	second, found := p.placeOf(secondID)
+	if !found {
+		return fmt.Errorf("no caught pokemon has the ID '%s'", secondID)
+	}
+	if first == second {
+		return fmt.Errorf("cannot swap #%s with itself", firstID)
+	}
+
+	*p.slot(first) = secondID
+	*p.slot(second) = firstID
+	p.version++
+
+	return nil
+}
+
+// returns the IDs in the party and in each box, for saving
+func (p *Pokedex) Layout() ([]string, [][]string) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	return p.layout()
+}
+
+// expects the lock to be held
+func (p *Pokedex) layout() ([]string, [][]string) {
+	boxes := make([][]string, 0, len(p.boxes))
+	for _, box := range p.boxes {
+		boxes = append(boxes, slices.Clone(box))
+	}
+
+	return slices.Clone(p.party), boxes
+}
+
+// replaces where every caught pokemon is kept, for loading from save.
+// IDs that are not caught or are listed twice are left out,
+// and caught pokemon that are not listed are put away as if just caught
+func (p *Pokedex) SetLayout(party []string, boxes [][]string) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	p.party = slices.Clone(party)
+	p.boxes = make([][]string, 0, len(boxes))
+	for _, box := range boxes {
+		p.boxes = append(p.boxes, slices.Clone(box))
+	}
+
+	p.arrange()
+	p.version++
+}
+
+// returns the caught pokemon with the IDs, in the same order,
+// expects the lock to be held
+func (p *Pokedex) individuals(IDs []string) []CaughtPokemon {
+	individuals := make([]CaughtPokemon, 0, len(IDs))
+	for _, ID := range IDs {
+		individuals = append(individuals, p.caught[ID])
+	}
+	return individuals
+}
+
+// expects the lock to be held
+func (p *Pokedex) placeOf(ID string) (Place, bool) {
+	if i := slices.Index(p.party, ID); i >= 0 {
+		return Place{Box: 0, Slot: i + 1}, true
+	}
+	for box, IDs := range p.boxes {
+		if i := slices.Index(IDs, ID); i >= 0 {
+			return Place{Box: box + 1, Slot: i + 1}, true
+		}
+	}
+	return Place{}, false
+}
+
+// returns the entry of the layout at the place, which must exist,
+// expects the lock to be held
+func (p *Pokedex) slot(place Place) *string {
+	if place.InParty() {
+		return &p.party[place.Slot-1]
+	}
+	return &p.boxes[place.Box-1][place.Slot-1]
+}
+
+// puts a new pokemon in the party, or in the PC when the party is full.
+// returns the box it was put in, or 0 for the party.
+// expects the lock to be held
+func (p *Pokedex) route(ID string) int {
+	if len(p.party) < PartySize {
+		p.party = append(p.party, ID)
+		return 0
+	}
+	return p.store(ID)
+}
+
+// puts a pokemon in the first box with space, adding a box when every box is full,
+// expects the lock to be held
+func (p *Pokedex) store(ID string) int {
+	for i, box := range p.boxes {
+		if len(box) < BoxSize {
+			p.boxes[i] = append(box, ID)
+			return i + 1
+		}
+	}
+
+	p.boxes = append(p.boxes, []string{ID})
+	return len(p.boxes)
+}
+
+// removes empty boxes from the end of the PC, expects the lock to be held
+func (p *Pokedex) trimBoxes() {
+	for len(p.boxes) > 0 && len(p.boxes[len(p.boxes)-1]) == 0 {
+		p.boxes = p.boxes[:len(p.boxes)-1]
+	}
+}
+
+// makes the layout match the caught pokemon: IDs that are not caught or are
+// listed twice are removed, and every caught pokemon that is not listed is
+// put away, oldest catch first. expects the lock to be held
+func (p *Pokedex) arrange() {
+	placed := make(map[string]bool)
+	// pokemon past the size are put away again with the missing ones
+	keep := func(IDs []string, size int) []string {
+		kept := []string{}
+		for _, ID := range IDs {
+			if _, caught := p.caught[ID]; caught && !placed[ID] && len(kept) < size {
+				placed[ID] = true
+				kept = append(kept, ID)
+			}
+		}
+		return kept
+	}
+
+	p.party = keep(p.party, PartySize)
+	for i, box := range p.boxes {
+		p.boxes[i] = keep(box, BoxSize)
+	}
+
+	var missing []CaughtPokemon
+	for ID, individual := range p.caught {
+		if !placed[ID] {
+			missing = append(missing, individual)
+		}
+	}
+	sort.Slice(missing, func(i, j int) bool {
+		if !missing[i].CaughtAt.Equal(missing[j].CaughtAt) {
+			return missing[i].CaughtAt.Before(missing[j].CaughtAt)
+		}
+		return missing[i].ID < missing[j].ID
+	})
+	for _, individual := range missing {
+		p.route(individual.ID)
+	}
+
+	p.trimBoxes()
+}
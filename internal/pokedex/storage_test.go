@@ -0,0 +1,149 @@
+package pokedex_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	pokeapi "github.com/nicholasss/pokedexcli/internal/pokeapi"
+	pokedex "github.com/nicholasss/pokedexcli/internal/pokedex"
+)
+
+// returns a pokedex with a number of pidgey caught a day apart, and their IDs
+func storagePokedex(count int) (*pokedex.Pokedex, []string) {
+	dex := pokedex.NewPokedex()
+	pidgey := pokeapi.PokemonInfo{ID: 16, Name: "pidgey"}
+
+	IDs := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		caught := dex.Add(pidgey, pokedex.CaughtPokemon{
+			CaughtAt: time.Date(2025, 1, 1+i, 0, 0, 0, 0, time.UTC),
+		})
+		IDs = append(IDs, caught.ID)
+	}
+
+	return dex, IDs
+}
+
+func TestStorageRouting(t *testing.T) {
+	cases := []struct {
+		caught    int
+		party     int
+		boxes     int
+		lastPlace pokedex.Place
+	}{
+		{caught: 1, party: 1, boxes: 1, lastPlace: pokedex.Place{Box: 0, Slot: 1}},
+		{caught: 6, party: 6, boxes: 1, lastPlace: pokedex.Place{Box: 0, Slot: 6}},
+		{caught: 7, party: 6, boxes: 1, lastPlace: pokedex.Place{Box: 1, Slot: 1}},
+		{caught: 36, party: 6, boxes: 1, lastPlace: pokedex.Place{Box: 1, Slot: 30}},
+		{caught: 37, party: 6, boxes: 2, lastPlace: pokedex.Place{Box: 2, Slot: 1}},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			dex, IDs := storagePokedex(c.caught)
+
+			if len(dex.Party()) != c.party {
+				t.Errorf("expected %d in the party, got %d", c.party, len(dex.Party()))
+				return
+			}
+			if dex.BoxCount() != c.boxes {
+				t.Errorf("expected %d boxes, got %d", c.boxes, dex.BoxCount())
+				return
+			}
+			if place, _ := dex.PlaceOf(IDs[len(IDs)-1]); place != c.lastPlace {
+				t.Errorf("expected the last catch at %+v, got %+v", c.lastPlace, place)
+				return
+			}
+		})
+	}
+}
+
+func TestDepositWithdrawSwap(t *testing.T) {
+	dex, IDs := storagePokedex(7)
+
+	if err := dex.Withdraw(IDs[6]); err == nil {
+		t.Errorf("expected withdrawing into a full party to fail")
+		return
+	}
+
+	box, err := dex.Deposit(IDs[0])
+	if err != nil || box != 1 {
+		t.Errorf("expected to deposit into box 1, got box %d: %v", box, err)
+		return
+	}
+	if err := dex.Withdraw(IDs[6]); err != nil {
+		t.Errorf("unable to withdraw: %s", err)
+		return
+	}
+	if place, _ := dex.PlaceOf(IDs[6]); place != (pokedex.Place{Box: 0, Slot: 6}) {
+		t.Errorf("expected the withdrawn pokemon at the end of the party, got %+v", place)
+		return
+	}
+
+	// the first pokemon is the only one left in box 1
+	if err := dex.Swap(IDs[1], IDs[0]); err != nil {
+		t.Errorf("unable to swap: %s", err)
+		return
+	}
+	if place, _ := dex.PlaceOf(IDs[0]); place != (pokedex.Place{Box: 0, Slot: 1}) {
+		t.Errorf("expected the swapped pokemon to lead the party, got %+v", place)
+		return
+	}
+	if place, _ := dex.PlaceOf(IDs[1]); place != (pokedex.Place{Box: 1, Slot: 1}) {
+		t.Errorf("expected the swapped pokemon in box 1, got %+v", place)
+		return
+	}
+
+	if err := dex.Swap(IDs[0], IDs[0]); err == nil {
+		t.Errorf("expected swapping a pokemon with itself to fail")
+	}
+	if _, err := dex.Deposit(IDs[1]); err == nil {
+		t.Errorf("expected depositing a boxed pokemon to fail")
+	}
+	if err := dex.Withdraw("missing"); err == nil {
+		t.Errorf("expected withdrawing an unknown ID to fail")
+	}
+
+	// the party always keeps one pokemon
+	single, singleIDs := storagePokedex(1)
+	if _, err := single.Deposit(singleIDs[0]); err == nil {
+		t.Errorf("expected depositing the last pokemon in the party to fail")
+	}
+}
+
+func TestSetLayout(t *testing.T) {
+	dex, IDs := storagePokedex(8)
+
+	// unknown and repeated IDs are left out, and unlisted pokemon are put away oldest first
+	dex.SetLayout(
+		[]string{IDs[7], "missing", IDs[7]},
+		[][]string{{}, {IDs[0], IDs[1]}},
+	)
+
+	party, boxes := dex.Layout()
+	expectedParty := []string{IDs[7], IDs[2], IDs[3], IDs[4], IDs[5], IDs[6]}
+	if fmt.Sprint(party) != fmt.Sprint(expectedParty) {
+		t.Errorf("expected party %v, got %v", expectedParty, party)
+		return
+	}
+	expectedBoxes := [][]string{{}, {IDs[0], IDs[1]}}
+	if fmt.Sprint(boxes) != fmt.Sprint(expectedBoxes) {
+		t.Errorf("expected boxes %v, got %v", expectedBoxes, boxes)
+		return
+	}
+
+	// the party never holds more than six
+	dex.SetLayout(IDs, nil)
+	if party := dex.Party(); len(party) != pokedex.PartySize {
+		t.Errorf("expected a party of %d, got %d", pokedex.PartySize, len(party))
+		return
+	}
+	if box, _ := dex.Box(1); len(box) != 2 || box[0].ID != IDs[6] {
+		t.Errorf("expected the rest in box 1, got %+v", box)
+		return
+	}
+	if _, err := dex.Box(2); err == nil {
+		t.Errorf("expected box 2 not to exist")
+	}
+}
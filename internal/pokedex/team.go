@@ -0,0 +1,75 @@
+package pokedex
+
+import "slices"
+
+// A value for each stat, used for both EVs and IVs.
+type TeamStats struct {
+	HP             int `json:"hp"`
+	Attack         int `json:"attack"`
+	Defense        int `json:"defense"`
+	SpecialAttack  int `json:"special_attack"`
+	SpecialDefense int `json:"special_defense"`
+	Speed          int `json:"speed"`
+}
+
+// A single Pokemon planned for the team, read from and written to pastes by the showdown package.
+// names are kept as PokeAPI names, such as "volt-tackle"
+type TeamMember struct {
+	Species string `json:"species"`
+	// the form written after the species, such as "therian" for Landorus-Therian,
+	// empty when the species is given on its own
+	Form     string `json:"form,omitempty"`
+	Nickname string `json:"nickname,omitempty"`
+	// M or F, empty when the gender is not chosen
+	Gender  string    `json:"gender,omitempty"`
+	Item    string    `json:"item,omitempty"`
+	Ability string    `json:"ability,omitempty"`
+	Level   int       `json:"level"`
+	Shiny   bool      `json:"shiny,omitempty"`
+	EVs     TeamStats `json:"evs"`
+	IVs     TeamStats `json:"ivs"`
+	Nature  string    `json:"nature,omitempty"`
+	Moves   []string  `json:"moves"`
+	// lines of a paste that are not read, such as the Tera Type, kept so they are written back as they were
+	Other []string `json:"other,omitempty"`
+}
+
+// returns the PokeAPI name of the pokemon, the species followed by its form
+func (m TeamMember) Pokemon() string {
+	if m.Form == "" {
+		return m.Species
+	}
+	return m.Species + "-" + m.Form
+}
+
+// adds every stat together
+func (s TeamStats) Total() int {
+	return s.HP + s.Attack + s.Defense + s.SpecialAttack + s.SpecialDefense + s.Speed
+}
+
+// returns a copy of the team that shares no moves or lines with it
+func cloneTeam(team []TeamMember) []TeamMember {
+	cloned := slices.Clone(team)
+	for i := range cloned {
+		cloned[i].Moves = slices.Clone(cloned[i].Moves)
+		cloned[i].Other = slices.Clone(cloned[i].Other)
+	}
+	return cloned
+}
+
+// returns the team, in the order it was imported
+func (p *Pokedex) Team() []TeamMember {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	return cloneTeam(p.team)
+}
+
+// replaces the team
+func (p *Pokedex) SetTeam(team []TeamMember) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	p.team = cloneTeam(team)
+	p.version++
+}
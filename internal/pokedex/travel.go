@@ -0,0 +1,39 @@
+package pokedex
+
+import "strings"
+
+// words in the names of location areas that are underground
+var caveWords = []string{"cave", "cavern", "tunnel", "grotto"}
+
+// reports whether a location area is a cave, where Dusk Balls work best
+func IsCave(area string) bool {
+	for _, word := range strings.Split(area, "-") {
+		for _, caveWord := range caveWords {
+			if word == caveWord {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// returns the location area the trainer is in, empty before they first travel
+func (p *Pokedex) Location() string {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	return p.location
+}
+
+// moves the trainer to a location area
+func (p *Pokedex) SetLocation(area string) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	if p.location == area {
+		return
+	}
+
+	p.location = area
+	p.version++
+}
@@ -0,0 +1,204 @@
+package savestate
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// the number of previous saves kept as backups
+const maxBackups = 5
+
+// backups are named after the save file and the time it was replaced
+const backupTimeLayout = "20060102T150405.000000000"
+
+// A previous save that was replaced.
+type Backup struct {
+	Name     string
+	Path     string
+	Replaced time.Time
+}
+
+// returns the folder holding the backups for a save file
+func backupDir(path string) string {
+	return filepath.Join(filepath.Dir(path), "backups")
+}
+
+// writes the data to a temporary file next to the path, and then renames it into place.
+// a crash while writing leaves the previous save untouched.
+// the previous save is kept as a backup before it is replaced
+func writeAtomic(path string, data []byte) (err error) {
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	// the temporary file is only left behind if something failed
+	defer func() {
+		if err != nil {
+			temp.Close()
+			os.Remove(temp.Name())
+		}
+	}()
+
+	if _, err = temp.Write(data); err != nil {
+		return err
+	}
+	if err = temp.Sync(); err != nil {
+		return err
+	}
+	if err = temp.Close(); err != nil {
+		return err
+	}
+
+	if err = backupSave(path); err != nil {
+		return fmt.Errorf("unable to back up previous save: %w", err)
+	}
+
+	if err = os.Rename(temp.Name(), path); err != nil {
+		return err
+	}
+
+	// makes sure the rename itself is written to disk,
+	// not every platform allows syncing a folder so this is best effort
+	if dir, dirErr := os.Open(filepath.Dir(path)); dirErr == nil {
+		dir.Sync()
+		dir.Close()
+	}
+
+	// old backups that cannot be removed are tried again on the next save
+	pruneBackups(path)
+
+	return nil
+}
+
+// keeps a copy of the save at the path, if there is one
+func backupSave(path string) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+
+	if err := os.MkdirAll(backupDir(path), 0755); err != nil {
+		return err
+	}
+
+	backupName := filepath.Base(path) + "." + time.Now().UTC().Format(backupTimeLayout)
+	backupPath := filepath.Join(backupDir(path), backupName)
+
+	// a hard link is instant and the save is never missing,
+	// a copy is made where links are not supported
+	if err := os.Link(path, backupPath); err == nil {
+		return nil
+	}
+
+	return copyFile(path, backupPath)
+}
+
+func copyFile(from, to string) error {
+	source, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	destination, err := os.Create(to)
+	if err != nil {
+		return err
+	}
+	defer destination.Close()
+
+	if _, err := io.Copy(destination, source); err != nil {
+		return err
+	}
+
+	return destination.Sync()
+}
+
+// removes the oldest backups so that only maxBackups are kept
+func pruneBackups(path string) error {
+	backups, err := listBackups(path)
+	if err != nil {
+		return err
+	}
+
+	for i := maxBackups; i < len(backups); i++ {
+		if err := os.Remove(backups[i].Path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// returns the backups of the save at the path, newest first
+func ListBackups(path string) ([]Backup, error) {
+	mux.Lock()
+	defer mux.Unlock()
+
+	return listBackups(path)
+}
+
+// expects the lock to be held, or to be called while saving
+func listBackups(path string) ([]Backup, error) {
+	entries, err := os.ReadDir(backupDir(path))
+	if os.IsNotExist(err) {
+		return []Backup{}, nil
+	} else if err != nil {
+		return []Backup{}, err
+	}
+
+	prefix := filepath.Base(path) + "."
+
+	backups := []Backup{}
+	for _, entry := range entries {
+		timestamp, ok := strings.CutPrefix(entry.Name(), prefix)
+		if !ok {
+			continue
+		}
+
+		// skips other files whose names happen to share the prefix
+		replaced, err := time.Parse(backupTimeLayout, timestamp)
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, Backup{
+			Name:     entry.Name(),
+			Path:     filepath.Join(backupDir(path), entry.Name()),
+			Replaced: replaced,
+		})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Replaced.After(backups[j].Replaced)
+	})
+
+	return backups, nil
+}
+
+// replaces the save at the path with the backup.
+// the current save becomes a backup itself, so a restore can be undone
+func RestoreBackup(path string, backup Backup) error {
+	mux.Lock()
+	defer mux.Unlock()
+
+	data, err := os.ReadFile(backup.Path)
+	if err != nil {
+		return fmt.Errorf("unable to read backup: %w", err)
+	}
+
+	// a backup that cannot be decoded would only replace a good save with a bad one
+	if _, _, err := decodeSave(data, nil); err != nil {
+		return fmt.Errorf("backup is not a valid save: %w", err)
+	}
+
+	return writeAtomic(path, data)
+}
@@ -0,0 +1,270 @@
+package savestate
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	pokeapi "github.com/nicholasss/pokedexcli/internal/pokeapi"
+	pokedex "github.com/nicholasss/pokedexcli/internal/pokedex"
+)
+
+// the first schema version with a checksum, older saves cannot be checked
+const checksumSinceVersion = 4
+
+// API names are lowercase words joined by dashes
+var validPokemonName = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// the key used to sign saves, set once at startup
+var integrityKey []byte
+
+// A single entry of a save that was left out, and why.
+type EntryProblem struct {
+	// the list in the save the entry is in, species, caught, seen or bag
+	List string
+	// the position of the entry in its list, starting at 1
+	Position int
+	Name     string
+	Reason   string
+}
+
+// What was found wrong with a save while reading it.
+type IntegrityReport struct {
+	// the save was changed outside of the pokedex, or damaged
+	ChecksumMismatch bool
+	Problems         []EntryProblem
+}
+
+// reports whether nothing was found wrong
+func (r IntegrityReport) OK() bool {
+	return !r.ChecksumMismatch && len(r.Problems) == 0
+}
+
+// Returned when a save fails its integrity checks and was not repaired.
+type IntegrityError struct {
+	Report IntegrityReport
+}
+
+func (e *IntegrityError) Error() string {
+	var reasons []string
+	if e.Report.ChecksumMismatch {
+		reasons = append(reasons, "checksum does not match")
+	}
+	if len(e.Report.Problems) > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d bad entries", len(e.Report.Problems)))
+	}
+
+	return "save failed its integrity checks: " + strings.Join(reasons, ", ")
+}
+
+// sets the key used to sign and check saves
+func SetIntegrityKey(key []byte) {
+	mux.Lock()
+	defer mux.Unlock()
+
+	integrityKey = key
+}
+
+// reads the key at the path, creating a new random key if there is none.
+// saves signed on another machine will not match unless the key is copied too
+func LoadOrCreateKey(path string) ([]byte, error) {
+	encoded, err := os.ReadFile(path)
+	if err == nil {
+		return hex.DecodeString(strings.TrimSpace(string(encoded)))
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	// only the user should be able to read the key
+	if err := os.WriteFile(path, []byte(hex.EncodeToString(key)), 0600); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// finds the checksum in an encoded save, the value is the first group
+var checksumField = regexp.MustCompile(`"checksum"\s*:\s*"([0-9a-f]*)"`)
+
+// returns the checksum of an encoded save whose checksum is empty,
+// expects the lock to be held
+func checksum(data []byte) string {
+	mac := hmac.New(sha256.New, integrityKey)
+	mac.Write(data)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// fills in the empty checksum of an encoded save.
+// the checksum covers the bytes as written, so fields added to records later
+// do not change the checksum of saves that are already on disk
+func sign(data []byte) ([]byte, error) {
+	location := checksumField.FindSubmatchIndex(data)
+	if location == nil || location[2] != location[3] {
+		return nil, errors.New("save has no empty checksum to fill in")
+	}
+
+	signed := make([]byte, 0, len(data)+sha256.Size*2)
+	signed = append(signed, data[:location[2]]...)
+	signed = append(signed, checksum(data)...)
+	signed = append(signed, data[location[3]:]...)
+
+	return signed, nil
+}
+
+// reports whether the checksum in an encoded save matches the rest of it
+func checksumMatches(data []byte) bool {
+	location := checksumField.FindSubmatchIndex(data)
+	if location == nil {
+		return false
+	}
+
+	found := data[location[2]:location[3]]
+
+	unsigned := make([]byte, 0, len(data))
+	unsigned = append(unsigned, data[:location[2]]...)
+	unsigned = append(unsigned, data[location[3]:]...)
+
+	return hmac.Equal([]byte(checksum(unsigned)), found)
+}
+
+// the lists in a save that entries are checked in
+const (
+	speciesList = "species"
+	caughtList  = "caught"
+	seenList    = "seen"
+	bagList     = "bag"
+)
+
+// the highest level a caught pokemon can have
+const maxLevel = 100
+
+// caught pokemon IDs are short lowercase hex
+var validCaughtID = regexp.MustCompile(`^[0-9a-f]{1,16}$`)
+
+// splits the species data into what can be loaded and the problems with the rest
+func validateSpecies(species []pokeapi.PokemonInfo, positions []int) ([]pokeapi.PokemonInfo, []EntryProblem) {
+	valid := []pokeapi.PokemonInfo{}
+	var problems []EntryProblem
+	seen := make(map[string]bool)
+
+	for i, pokemon := range species {
+		reason := speciesProblem(pokemon)
+		if reason == "" && seen[pokemon.Name] {
+			reason = "duplicate entry"
+		}
+
+		if reason != "" {
+			problems = append(problems, EntryProblem{
+				List:     speciesList,
+				Position: positions[i],
+				Name:     pokemon.Name,
+				Reason:   reason,
+			})
+			continue
+		}
+
+		seen[pokemon.Name] = true
+		valid = append(valid, pokemon)
+	}
+
+	return valid, problems
+}
+
+// returns why the data of a species cannot be loaded, or nothing when it can
+func speciesProblem(pokemon pokeapi.PokemonInfo) string {
+	switch {
+	case pokemon.Name == "":
+		return "missing name"
+	case !validPokemonName.MatchString(pokemon.Name):
+		return "invalid name"
+	case pokemon.ID < 0:
+		return "invalid id"
+	case pokemon.Height < 0 || pokemon.Weight < 0 || pokemon.BaseExperience < 0:
+		return "invalid size or experience"
+	}
+
+	for _, stat := range pokemon.StatList {
+		if stat.BaseStat < 0 || stat.BaseStat > pokeapi.MaxBaseStat {
+			return "invalid stats"
+		}
+	}
+
+	return ""
+}
+
+// splits the caught pokemon into those that can be loaded and the problems with the rest.
+// a caught pokemon can only be loaded when the data of its species was
+func validateCaught(caught []pokedex.CaughtPokemon, positions []int, species []pokeapi.PokemonInfo) ([]pokedex.CaughtPokemon, []EntryProblem) {
+	valid := []pokedex.CaughtPokemon{}
+	var problems []EntryProblem
+
+	// anything caught this far ahead is from a wrong clock or an edit
+	latest := time.Now().Add(24 * time.Hour)
+
+	known := make(map[string]bool)
+	for _, pokemon := range species {
+		known[pokemon.Name] = true
+	}
+	seen := make(map[string]bool)
+
+	for i, individual := range caught {
+		reason := caughtProblem(individual, latest)
+		switch {
+		case reason != "":
+		case !known[individual.Species]:
+			reason = "missing species data"
+		case seen[individual.ID]:
+			reason = "duplicate id"
+		}
+
+		if reason != "" {
+			problems = append(problems, EntryProblem{
+				List:     caughtList,
+				Position: positions[i],
+				Name:     individual.Species,
+				Reason:   reason,
+			})
+			continue
+		}
+
+		seen[individual.ID] = true
+		valid = append(valid, individual)
+	}
+
+	return valid, problems
+}
+
+// returns why a caught pokemon cannot be loaded, or nothing when it can
+func caughtProblem(individual pokedex.CaughtPokemon, latest time.Time) string {
+	switch {
+	case !validCaughtID.MatchString(individual.ID):
+		return "invalid id"
+	case individual.Species == "":
+		return "missing species"
+	case individual.CaughtAt.After(latest):
+		return "caught in the future"
+	case individual.Level < 0 || individual.Level > maxLevel:
+		return "invalid level"
+	}
+
+	switch individual.Gender {
+	case "", pokedex.Male, pokedex.Female, pokedex.Genderless:
+	default:
+		return "invalid gender"
+	}
+
+	return ""
+}
@@ -0,0 +1,418 @@
+package savestate
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	pokeapi "github.com/nicholasss/pokedexcli/internal/pokeapi"
+	pokedex "github.com/nicholasss/pokedexcli/internal/pokedex"
+)
+
+// The version of the save layout written by SavePokedex.
+// Bump it, and add a migration from the previous version,
+// whenever existing saves would need their data changed to be read.
+// Adding a field that is fine being empty in older saves does not need a bump.
+const CurrentSchemaVersion = 7
+
+// Upgrades a save by one version.
+// Migrations work on the raw fields so that older layouts
+// do not need their own types kept around.
+type migration func(save map[string]json.RawMessage) error
+
+// Migrations keyed by the version they upgrade from.
+//
+// 1: only the list of names was saved
+// 2: full records were added next to the list of names
+// 3: the version is saved and the list of names removed
+// 4: a checksum is saved, older saves cannot be checked
+// 5: records are split into species data and caught pokemon with their own IDs
+// 6: a bag of items is saved, older saves are given the starter bag
+// 7: money is always saved, older saves without it are given the starter money
+var migrations = map[int]migration{
+	1: migrateV1ToV2,
+	2: migrateV2ToV3,
+	3: migrateV3ToV4,
+	4: migrateV4ToV5,
+	5: migrateV5ToV6,
+	6: migrateV6ToV7,
+}
+
+// decodes a save of any version, upgrading it step by step to the current version.
+// entries that fail the integrity checks are left out and reported
+func DecodeSave(data []byte) (SaveFile, IntegrityReport, error) {
+	mux.Lock()
+	defer mux.Unlock()
+
+	return decodeSave(data, nil)
+}
+
+// expects the lock to be held, knownPokemon checks saves that only have names
+func decodeSave(data []byte, knownPokemon func(string) bool) (SaveFile, IntegrityReport, error) {
+	var save map[string]json.RawMessage
+	if err := json.Unmarshal(data, &save); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			line := 1 + bytes.Count(data[:syntaxErr.Offset], []byte("\n"))
+			return SaveFile{}, IntegrityReport{}, fmt.Errorf("save is damaged near line %d: %w", line, err)
+		}
+		return SaveFile{}, IntegrityReport{}, fmt.Errorf("unable to decode save: %w", err)
+	}
+
+	originalVersion, err := schemaVersion(save)
+	if err != nil {
+		return SaveFile{}, IntegrityReport{}, err
+	}
+
+	if originalVersion > CurrentSchemaVersion {
+		return SaveFile{}, IntegrityReport{}, fmt.Errorf("save has schema version %d, newer than the supported version %d", originalVersion, CurrentSchemaVersion)
+	}
+
+	for version := originalVersion; version < CurrentSchemaVersion; version++ {
+		migrate, ok := migrations[version]
+		if !ok {
+			return SaveFile{}, IntegrityReport{}, fmt.Errorf("no migration from schema version %d", version)
+		}
+
+		if err := migrate(save); err != nil {
+			return SaveFile{}, IntegrityReport{}, fmt.Errorf("unable to migrate save from schema version %d: %w", version, err)
+		}
+
+		save["schema_version"] = json.RawMessage(fmt.Sprint(version + 1))
+	}
+
+	// each entry is decoded on its own, so that one bad entry does not lose the rest
+	speciesEntries, err := rawEntries(save, "species")
+	if err != nil {
+		return SaveFile{}, IntegrityReport{}, err
+	}
+	caughtEntries, err := rawEntries(save, "caught")
+	if err != nil {
+		return SaveFile{}, IntegrityReport{}, err
+	}
+	seenEntries, err := rawEntries(save, "seen")
+	if err != nil {
+		return SaveFile{}, IntegrityReport{}, err
+	}
+	bagEntries, err := rawEntries(save, "bag")
+	if err != nil {
+		return SaveFile{}, IntegrityReport{}, err
+	}
+	delete(save, "species")
+	delete(save, "caught")
+	delete(save, "seen")
+	delete(save, "bag")
+
+	header, err := json.Marshal(save)
+	if err != nil {
+		return SaveFile{}, IntegrityReport{}, err
+	}
+
+	var decoded SaveFile
+	if err := json.Unmarshal(header, &decoded); err != nil {
+		return SaveFile{}, IntegrityReport{}, fmt.Errorf("unable to decode save: %w", err)
+	}
+
+	var report IntegrityReport
+
+	var species []pokeapi.PokemonInfo
+	var speciesPositions []int
+	for i, entry := range speciesEntries {
+		var pokemon pokeapi.PokemonInfo
+		if err := json.Unmarshal(entry, &pokemon); err != nil {
+			report.Problems = append(report.Problems, EntryProblem{
+				List:     speciesList,
+				Position: i + 1,
+				Reason:   "unreadable entry",
+			})
+			continue
+		}
+
+		if pokemon.ID == 0 && knownPokemon != nil && !knownPokemon(pokemon.Name) {
+			report.Problems = append(report.Problems, EntryProblem{
+				List:     speciesList,
+				Position: i + 1,
+				Name:     pokemon.Name,
+				Reason:   "unknown pokemon",
+			})
+			continue
+		}
+
+		species = append(species, pokemon)
+		speciesPositions = append(speciesPositions, i+1)
+	}
+
+	var caught []pokedex.CaughtPokemon
+	var caughtPositions []int
+	for i, entry := range caughtEntries {
+		var individual pokedex.CaughtPokemon
+		if err := json.Unmarshal(entry, &individual); err != nil {
+			report.Problems = append(report.Problems, EntryProblem{
+				List:     caughtList,
+				Position: i + 1,
+				Reason:   "unreadable entry",
+			})
+			continue
+		}
+
+		caught = append(caught, individual)
+		caughtPositions = append(caughtPositions, i+1)
+	}
+
+	var seen []string
+	for i, entry := range seenEntries {
+		var name string
+		if err := json.Unmarshal(entry, &name); err != nil || !validPokemonName.MatchString(name) {
+			report.Problems = append(report.Problems, EntryProblem{
+				List:     seenList,
+				Position: i + 1,
+				Name:     name,
+				Reason:   "invalid name",
+			})
+			continue
+		}
+		seen = append(seen, name)
+	}
+	decoded.Seen = seen
+
+	bag := []pokedex.Item{}
+	inBag := make(map[string]bool)
+	for i, entry := range bagEntries {
+		var item pokedex.Item
+		reason := ""
+		switch {
+		case json.Unmarshal(entry, &item) != nil:
+			reason = "unreadable entry"
+		case !validPokemonName.MatchString(item.Name):
+			reason = "invalid name"
+		case item.Quantity < 1 || item.Quantity > pokedex.MaxQuantity:
+			reason = "invalid quantity"
+		case inBag[item.Name]:
+			reason = "duplicate entry"
+		}
+
+		if reason != "" {
+			report.Problems = append(report.Problems, EntryProblem{
+				List:     bagList,
+				Position: i + 1,
+				Name:     item.Name,
+				Reason:   reason,
+			})
+			continue
+		}
+
+		inBag[item.Name] = true
+		bag = append(bag, item)
+	}
+	decoded.Bag = bag
+
+	if originalVersion >= checksumSinceVersion {
+		report.ChecksumMismatch = !checksumMatches(data)
+	}
+
+	validSpecies, problems := validateSpecies(species, speciesPositions)
+	decoded.Species = validSpecies
+	report.Problems = append(report.Problems, problems...)
+
+	validCaught, problems := validateCaught(caught, caughtPositions, validSpecies)
+	decoded.Caught = validCaught
+	report.Problems = append(report.Problems, problems...)
+
+	// problems are listed in the order the lists are in the save
+	listOrder := map[string]int{speciesList: 0, caughtList: 1, seenList: 2, bagList: 3}
+	sort.SliceStable(report.Problems, func(i, j int) bool {
+		if report.Problems[i].List != report.Problems[j].List {
+			return listOrder[report.Problems[i].List] < listOrder[report.Problems[j].List]
+		}
+		return report.Problems[i].Position < report.Problems[j].Position
+	})
+
+	return decoded, report, nil
+}
+
+// returns each entry of a list in the save, without decoding them
+func rawEntries(save map[string]json.RawMessage, field string) ([]json.RawMessage, error) {
+	entries := []json.RawMessage{}
+	if raw, ok := save[field]; ok {
+		if err := json.Unmarshal(raw, &entries); err != nil {
+			return nil, fmt.Errorf("unable to decode the list of %s: %w", field, err)
+		}
+	}
+
+	return entries, nil
+}
+
+// saves from before the version was stored are told apart by their fields
+func schemaVersion(save map[string]json.RawMessage) (int, error) {
+	raw, ok := save["schema_version"]
+	if !ok {
+		if _, hasRecords := save["pokemon"]; hasRecords {
+			return 2, nil
+		}
+		return 1, nil
+	}
+
+	var version int
+	if err := json.Unmarshal(raw, &version); err != nil {
+		return 0, fmt.Errorf("unable to read schema version: %w", err)
+	}
+
+	return version, nil
+}
+
+// turns each name into a record that only has its name,
+// the rest of the record is requested again when loaded
+func migrateV1ToV2(save map[string]json.RawMessage) error {
+	var names []string
+	if raw, ok := save["pokedex_list"]; ok {
+		if err := json.Unmarshal(raw, &names); err != nil {
+			return err
+		}
+	}
+
+	// the save time is the closest to when each was caught
+	var saveTime time.Time
+	if raw, ok := save["save_time"]; ok {
+		if err := json.Unmarshal(raw, &saveTime); err != nil {
+			return err
+		}
+	}
+
+	type nameOnly struct {
+		Name string `json:"name"`
+	}
+	type recordV2 struct {
+		Pokemon  nameOnly  `json:"pokemon"`
+		CaughtAt time.Time `json:"caught_at"`
+	}
+
+	records := make([]recordV2, 0, len(names))
+	for _, name := range names {
+		records = append(records, recordV2{
+			Pokemon:  nameOnly{Name: name},
+			CaughtAt: saveTime,
+		})
+	}
+
+	raw, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+
+	save["pokemon"] = raw
+	return nil
+}
+
+// the list of names is the same as the names in the records
+func migrateV2ToV3(save map[string]json.RawMessage) error {
+	delete(save, "pokedex_list")
+	return nil
+}
+
+// nothing changes, a save without a checksum is only trusted up to this version
+func migrateV3ToV4(save map[string]json.RawMessage) error {
+	return nil
+}
+
+// splits each record into the data of its species and a caught pokemon.
+// the names were unique within a save, so each ID is made from the name and the
+// save time, it stays the same every time the save is migrated but differs between saves.
+// entries that cannot be read are kept as they are, so that they are reported as bad
+func migrateV4ToV5(save map[string]json.RawMessage) error {
+	records, err := rawEntries(save, "pokemon")
+	if err != nil {
+		return err
+	}
+
+	type recordV4 struct {
+		Pokemon struct {
+			Name string `json:"name"`
+		} `json:"pokemon"`
+		CaughtAt json.RawMessage `json:"caught_at,omitempty"`
+		Imported bool            `json:"imported,omitempty"`
+	}
+	type caughtV5 struct {
+		ID       string          `json:"id"`
+		Species  string          `json:"species"`
+		CaughtAt json.RawMessage `json:"caught_at,omitempty"`
+		Imported bool            `json:"imported,omitempty"`
+	}
+
+	species := []json.RawMessage{}
+	caught := []json.RawMessage{}
+	usedIDs := make(map[string]bool)
+	saveTime := string(save["save_time"])
+
+	for _, raw := range records {
+		var record recordV4
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(raw, &fields) != nil || json.Unmarshal(raw, &record) != nil {
+			species = append(species, raw)
+			caught = append(caught, raw)
+			continue
+		}
+
+		name := record.Pokemon.Name
+		ID := migratedID(saveTime, name, usedIDs)
+		usedIDs[ID] = true
+
+		individual, err := json.Marshal(caughtV5{
+			ID:       ID,
+			Species:  name,
+			CaughtAt: record.CaughtAt,
+			Imported: record.Imported,
+		})
+		if err != nil {
+			return err
+		}
+
+		species = append(species, fields["pokemon"])
+		caught = append(caught, individual)
+	}
+
+	rawSpecies, err := json.Marshal(species)
+	if err != nil {
+		return err
+	}
+	rawCaught, err := json.Marshal(caught)
+	if err != nil {
+		return err
+	}
+
+	save["species"] = rawSpecies
+	save["caught"] = rawCaught
+	delete(save, "pokemon")
+	return nil
+}
+
+// gives the save the bag a new trainer started with when bags were added,
+// so that older saves can still catch pokemon
+func migrateV5ToV6(save map[string]json.RawMessage) error {
+	save["bag"] = json.RawMessage(`[{"name":"poke-ball","quantity":10},{"name":"potion","quantity":3}]`)
+	return nil
+}
+
+// returns an ID in the same form as new catches, made from the save time and the name
+func migratedID(saveTime, name string, used map[string]bool) string {
+	for attempt := 0; ; attempt++ {
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%s#%s#%d", saveTime, name, attempt)))
+		ID := hex.EncodeToString(sum[:8])
+		if !used[ID] {
+			return ID
+		}
+	}
+}
+
+// gives the save the money a new trainer started with when money was added,
+// saves from before then have no money field rather than no money
+func migrateV6ToV7(save map[string]json.RawMessage) error {
+	if _, ok := save["money"]; !ok {
+		save["money"] = json.RawMessage(`3000`)
+	}
+	return nil
+}
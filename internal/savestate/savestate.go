@@ -1,21 +1,42 @@
 package savestate
 
 import (
-	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"reflect"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
+	pokeapi "github.com/nicholasss/pokedexcli/internal/pokeapi"
 	pokedex "github.com/nicholasss/pokedexcli/internal/pokedex"
 )
 
 type SaveFile struct {
-	SaveTime    time.Time `json:"save_time"`
-	PokedexList []string  `json:"pokedex_list"`
+	SchemaVersion int       `json:"schema_version"`
+	SaveTime      time.Time `json:"save_time"`
+	// signs the rest of the save, so that changes made outside of the pokedex are found
+	Checksum string                  `json:"checksum"`
+	Species  []pokeapi.PokemonInfo   `json:"species"`
+	Caught   []pokedex.CaughtPokemon `json:"caught"`
+	// every pokemon encountered, including those caught
+	Seen []string             `json:"seen,omitempty"`
+	Team []pokedex.TeamMember `json:"team,omitempty"`
+	Bag  []pokedex.Item       `json:"bag"`
+	// saves from before money was kept are given the starter money
+	Money int `json:"money"`
+	// every location area explored, items are only found the first time
+	Explored []string `json:"explored,omitempty"`
+	// the location area the trainer is in
+	Location string `json:"location,omitempty"`
+	// the IDs of the caught pokemon in the party and in each box of the PC,
+	// saves from before the PC was kept have every pokemon put away again
+	Party []string   `json:"party,omitempty"`
+	Boxes [][]string `json:"boxes,omitempty"`
 }
 
 var mux sync.Mutex
@@ -24,9 +45,16 @@ func init() {
 	mux = sync.Mutex{}
 }
 
-func SavePokedex(path string, pokedex *pokedex.Pokedex) error {
-	pokedexList, ok := pokedex.GetAll()
-	if !ok {
+func SavePokedex(path string, dex *pokedex.Pokedex) error {
+	// taken at once, so that a change while saving leaves the pokedex dirty
+	// instead of being half in the save
+	snapshot := dex.Snapshot()
+
+	// a trainer who only shopped or travelled still has something to save
+	newTrainer := snapshot.Money == pokedex.StarterMoney && snapshot.Location == "" &&
+		reflect.DeepEqual(itemCounts(snapshot.Bag), itemCounts(pokedex.StarterBag()))
+	if len(snapshot.Caught) == 0 && len(snapshot.Seen) == 0 && len(snapshot.Team) == 0 &&
+		len(snapshot.Explored) == 0 && newTrainer {
 		return errors.New("unable to get list from pokedex")
 	}
 
@@ -35,57 +63,266 @@ func SavePokedex(path string, pokedex *pokedex.Pokedex) error {
 	defer mux.Unlock()
 
 	newSave := SaveFile{
-		SaveTime:    time.Now(),
-		PokedexList: pokedexList,
+		SchemaVersion: CurrentSchemaVersion,
+		SaveTime:      time.Now(),
+		Species:       snapshot.Species,
+		Caught:        snapshot.Caught,
+		Seen:          snapshot.Seen,
+		Team:          snapshot.Team,
+		Bag:           snapshot.Bag,
+		Money:         snapshot.Money,
+		Explored:      snapshot.Explored,
+		Location:      snapshot.Location,
+		Party:         snapshot.Party,
+		Boxes:         snapshot.Boxes,
 	}
 
-	file, err := os.Create(path)
+	data, err := json.Marshal(newSave)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
 
-	data, err := json.Marshal(newSave)
+	data, err = sign(data)
 	if err != nil {
 		return err
 	}
 
-	dataReader := bytes.NewReader(data)
-
-	_, err = io.Copy(file, dataReader)
-	if err != nil {
+	if err := writeAtomic(path, data); err != nil {
 		return err
 	}
 
+	dex.MarkSaved(snapshot.Version)
+
 	fmt.Println("Saved Pokedex successfully.")
 	return nil
 }
 
-func LoadPokedex(path string, pokedex *pokedex.Pokedex) error {
+// How a save is loaded into a pokedex that already has pokemon.
+type LoadMode string
+
+const (
+	// the pokedex is replaced by the save
+	Replace LoadMode = "replace"
+	// pokemon only in the save are added, the pokedex keeps its own records on conflicts
+	Merge LoadMode = "merge"
+)
+
+// Changes how a save is loaded.
+type LoadOptions struct {
+	Mode LoadMode
+	// loads the entries that pass the integrity checks, instead of nothing
+	Repair bool
+	// reports whether a pokemon exists, used to check saves that only have names.
+	// when nil those names are not checked
+	KnownPokemon func(name string) bool
+}
+
+// What happened while loading a save.
+type LoadResult struct {
+	Conflicts []pokedex.Conflict
+	Report    IntegrityReport
+	// when merging, the items the save had more of, with the quantity taken from the save
+	ItemsFromSave []pokedex.Item
+	// when merging, whether the save had more money and its money was taken
+	MoneyFromSave bool
+}
+
+// reads and decodes the save at the path, without loading it
+func ReadSave(path string) (SaveFile, IntegrityReport, error) {
 	mux.Lock()
 	defer mux.Unlock()
 
+	return readSave(path, nil)
+}
+
+// expects the lock to be held
+func readSave(path string, knownPokemon func(string) bool) (SaveFile, IntegrityReport, error) {
 	file, err := os.Open(path)
 	if os.IsNotExist(err) {
 		fmt.Println("There is no save file to load.")
-		return err
+		return SaveFile{}, IntegrityReport{}, err
 	} else if err != nil {
-		return err
+		return SaveFile{}, IntegrityReport{}, err
 	}
 	defer file.Close()
 
-	var oldSave SaveFile
-	decoder := json.NewDecoder(file)
-	if err := decoder.Decode(&oldSave); err != nil {
-		return err
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return SaveFile{}, IntegrityReport{}, err
 	}
 
-	ok := pokedex.AddList(oldSave.PokedexList)
+	return decodeSave(data, knownPokemon)
+}
+
+// loads the save into the pokedex.
+// a save that fails its integrity checks is only loaded when repairing,
+// and then only the entries that passed
+func LoadPokedex(path string, dex *pokedex.Pokedex, opts LoadOptions) (LoadResult, error) {
+	mux.Lock()
+	defer mux.Unlock()
+
+	oldSave, report, err := readSave(path, opts.KnownPokemon)
+	if err != nil {
+		return LoadResult{}, err
+	}
+
+	result := LoadResult{Report: report}
+	if !report.OK() && !opts.Repair {
+		return result, &IntegrityError{Report: report}
+	}
+
+	// saves from before records were kept only have names,
+	// these need every pokemon requested again
+	ok := pokedex.CompleteSpecies(oldSave.Species)
 	if !ok {
-		return errors.New("unable to add list to pokedex:")
+		return result, errors.New("unable to add list to pokedex:")
+	}
+
+	// a repaired save differs from the file, so it stays unsaved
+	matchesFile := report.OK()
+
+	switch opts.Mode {
+	case Replace:
+		dex.Replace(oldSave.Species, oldSave.Caught)
+		dex.ReplaceSeen(oldSave.Seen)
+		dex.SetTeam(oldSave.Team)
+		dex.SetBag(oldSave.Bag)
+		dex.SetMoney(oldSave.Money)
+		dex.ReplaceExplored(oldSave.Explored)
+		dex.SetLocation(oldSave.Location)
+		dex.SetLayout(oldSave.Party, oldSave.Boxes)
+		// what was loaded is already on disk
+		if matchesFile {
+			dex.MarkSaved(dex.Version())
+		}
+
+	case Merge:
+		// the pokedex only matches the save if it had nothing the save does not
+		difference := dex.Diff(oldSave.Caught)
+		unsaved := dex.IsDirty() || len(difference.OnlyInPokedex) > 0 || len(difference.Conflicts) > 0
+
+		// the team is only taken from the save when there is none yet
+		team := dex.Team()
+		if len(team) > 0 && !reflect.DeepEqual(team, oldSave.Team) {
+			unsaved = true
+		}
+
+		// the larger amount of each item and of money is kept,
+		// so that nothing is lost and nothing is counted twice
+		bag := itemCounts(dex.Bag())
+		for name, quantity := range itemCounts(oldSave.Bag) {
+			if quantity > bag[name] {
+				bag[name] = quantity
+				result.ItemsFromSave = append(result.ItemsFromSave, pokedex.Item{Name: name, Quantity: quantity})
+			}
+		}
+		slices.SortFunc(result.ItemsFromSave, func(a, b pokedex.Item) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+		money := dex.Money()
+		if oldSave.Money > money {
+			money = oldSave.Money
+			result.MoneyFromSave = true
+		}
+		if !reflect.DeepEqual(bag, itemCounts(oldSave.Bag)) || money != oldSave.Money {
+			unsaved = true
+		}
+
+		saveExplored := make(map[string]bool)
+		for _, area := range oldSave.Explored {
+			saveExplored[area] = true
+		}
+		for _, area := range dex.Explored() {
+			if !saveExplored[area] {
+				unsaved = true
+				break
+			}
+		}
+
+		// the trainer stays where they are, unless they have not been anywhere yet
+		location := dex.Location()
+		if location != "" && location != oldSave.Location {
+			unsaved = true
+		}
+
+		// saves from before seen pokemon were kept only have their caught pokemon
+		saveSeen := make(map[string]bool)
+		for _, name := range oldSave.Seen {
+			saveSeen[name] = true
+		}
+		for _, individual := range oldSave.Caught {
+			saveSeen[individual.Species] = true
+		}
+		for _, name := range dex.Seen() {
+			if !saveSeen[name] {
+				unsaved = true
+				break
+			}
+		}
+
+		// the pokedex keeps its own party and boxes, pokemon only in the save are put away
+		party, boxes := dex.Layout()
+		keepLayout := len(party) > 0 || len(boxes) > 0
+
+		result.Conflicts = dex.Merge(oldSave.Species, oldSave.Caught)
+		dex.MarkSeen(oldSave.Seen...)
+		for _, area := range oldSave.Explored {
+			dex.MarkExplored(area)
+		}
+		if len(team) == 0 && len(oldSave.Team) > 0 {
+			dex.SetTeam(oldSave.Team)
+		}
+		if len(result.ItemsFromSave) > 0 {
+			items := make([]pokedex.Item, 0, len(bag))
+			for name, quantity := range bag {
+				items = append(items, pokedex.Item{Name: name, Quantity: quantity})
+			}
+			dex.SetBag(items)
+		}
+		if result.MoneyFromSave {
+			dex.SetMoney(money)
+		}
+		if location == "" {
+			dex.SetLocation(oldSave.Location)
+		}
+		if !keepLayout {
+			dex.SetLayout(oldSave.Party, oldSave.Boxes)
+		}
+		if !sameLayout(dex, oldSave.Party, oldSave.Boxes) {
+			unsaved = true
+		}
+		if !unsaved && matchesFile {
+			dex.MarkSaved(dex.Version())
+		}
+
+	default:
+		return result, fmt.Errorf("unknown load mode '%s'", opts.Mode)
 	}
 
 	time := oldSave.SaveTime
 	fmt.Println("Loaded save file from:", time.Local().String())
-	return nil
+	return result, nil
+}
+
+// reports whether the party and boxes of the pokedex are the ones in the save
+func sameLayout(dex *pokedex.Pokedex, party []string, boxes [][]string) bool {
+	dexParty, dexBoxes := dex.Layout()
+	if !slices.Equal(dexParty, party) || len(dexBoxes) != len(boxes) {
+		return false
+	}
+	for i, box := range dexBoxes {
+		if !slices.Equal(box, boxes[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// returns how many of each item there are, whatever order they are listed in
+func itemCounts(items []pokedex.Item) map[string]int {
+	counts := make(map[string]int)
+	for _, item := range items {
+		counts[item.Name] += item.Quantity
+	}
+	return counts
 }
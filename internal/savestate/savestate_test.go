@@ -0,0 +1,533 @@
+package savestate_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pokeapi "github.com/nicholasss/pokedexcli/internal/pokeapi"
+	pokedex "github.com/nicholasss/pokedexcli/internal/pokedex"
+	savestate "github.com/nicholasss/pokedexcli/internal/savestate"
+)
+
+// regenerates the golden files with: go test ./internal/savestate -update
+var update = flag.Bool("update", false, "update golden files")
+
+func TestDecodeSaveMigrations(t *testing.T) {
+	for version := 1; version <= savestate.CurrentSchemaVersion; version++ {
+		t.Run(fmt.Sprintf("Schema version %v", version), func(t *testing.T) {
+			inputPath := filepath.Join("testdata", fmt.Sprintf("v%d.json", version))
+			goldenPath := filepath.Join("testdata", fmt.Sprintf("v%d.golden.json", version))
+
+			input, err := os.ReadFile(inputPath)
+			if err != nil {
+				t.Errorf("unable to read save for version %d: %s", version, err)
+				return
+			}
+
+			decoded, report, err := savestate.DecodeSave(input)
+			if err != nil {
+				t.Errorf("unable to decode save: %s", err)
+				return
+			}
+
+			if !report.OK() {
+				t.Errorf("expected save to pass integrity checks, got %+v", report)
+				return
+			}
+
+			if decoded.SchemaVersion != savestate.CurrentSchemaVersion {
+				t.Errorf("expected schema version %d, got %d", savestate.CurrentSchemaVersion, decoded.SchemaVersion)
+				return
+			}
+
+			actual, err := json.MarshalIndent(decoded, "", "  ")
+			if err != nil {
+				t.Errorf("unable to marshal decoded save: %s", err)
+				return
+			}
+
+			if *update {
+				if err := os.WriteFile(goldenPath, actual, 0644); err != nil {
+					t.Errorf("unable to update golden file: %s", err)
+				}
+				return
+			}
+
+			expected, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Errorf("unable to read golden file: %s", err)
+				return
+			}
+
+			if !bytes.Equal(actual, expected) {
+				t.Errorf("migrated save differs from %s:\n%s", goldenPath, actual)
+			}
+		})
+	}
+}
+
+func TestDecodeSaveNewerVersion(t *testing.T) {
+	input := fmt.Sprintf(`{"schema_version": %d}`, savestate.CurrentSchemaVersion+1)
+
+	if _, _, err := savestate.DecodeSave([]byte(input)); err == nil {
+		t.Errorf("expected error decoding a save from a newer version")
+	}
+}
+
+func TestMigratedIDs(t *testing.T) {
+	input, err := os.ReadFile(filepath.Join("testdata", "v3.json"))
+	if err != nil {
+		t.Errorf("unable to read save: %s", err)
+		return
+	}
+	// another save with the same pokemon, made at another time
+	other := bytes.Replace(input, []byte("2025-04-01T12:00:00Z"), []byte("2025-04-02T12:00:00Z"), 1)
+
+	var IDs []string
+	for _, save := range [][]byte{input, input, other} {
+		decoded, _, err := savestate.DecodeSave(save)
+		if err != nil {
+			t.Errorf("unable to decode save: %s", err)
+			return
+		}
+		if len(decoded.Caught) != 1 {
+			t.Errorf("expected a single caught pokemon, got %d", len(decoded.Caught))
+			return
+		}
+		IDs = append(IDs, decoded.Caught[0].ID)
+	}
+
+	if IDs[0] != IDs[1] {
+		t.Errorf("expected the same save to migrate to the same ID, got %s and %s", IDs[0], IDs[1])
+	}
+	if IDs[0] == IDs[2] {
+		t.Errorf("expected saves made at different times to have different IDs, both got %s", IDs[0])
+	}
+	if len(IDs[0]) != 16 {
+		t.Errorf("expected a 64 bit ID, got %s", IDs[0])
+	}
+}
+
+// returns a pokedex holding a single pokemon, so that it can be saved
+func testPokedex(name string) *pokedex.Pokedex {
+	dex := pokedex.NewPokedex()
+	dex.Add(pokeapi.PokemonInfo{ID: 1, Name: name}, pokedex.CaughtPokemon{
+		CaughtAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+	return dex
+}
+
+func TestSaveBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.json")
+
+	// every save after the first keeps the one before it
+	const saves = 8
+	for i := 0; i < saves; i++ {
+		if err := savestate.SavePokedex(path, testPokedex(fmt.Sprintf("pokemon-%d", i))); err != nil {
+			t.Errorf("unable to save: %s", err)
+			return
+		}
+	}
+
+	backups, err := savestate.ListBackups(path)
+	if err != nil {
+		t.Errorf("unable to list backups: %s", err)
+		return
+	}
+
+	if len(backups) != 5 {
+		t.Errorf("expected 5 backups to be kept, got %d", len(backups))
+		return
+	}
+
+	for i := 1; i < len(backups); i++ {
+		if backups[i].Replaced.After(backups[i-1].Replaced) {
+			t.Errorf("expected backups to be sorted newest first")
+			return
+		}
+	}
+
+	leftovers, err := filepath.Glob(path + ".tmp-*")
+	if err != nil || len(leftovers) != 0 {
+		t.Errorf("expected no temporary files to be left, got %v", leftovers)
+	}
+
+	// the newest backup is the save before the last one
+	if err := savestate.RestoreBackup(path, backups[0]); err != nil {
+		t.Errorf("unable to restore backup: %s", err)
+		return
+	}
+
+	restored := pokedex.NewPokedex()
+	if _, err := savestate.LoadPokedex(path, restored, savestate.LoadOptions{Mode: savestate.Replace}); err != nil {
+		t.Errorf("unable to load restored save: %s", err)
+		return
+	}
+
+	expected := fmt.Sprintf("pokemon-%d", saves-2)
+	if _, ok := restored.Get(expected); !ok {
+		t.Errorf("expected restored save to hold %s", expected)
+	}
+}
+
+func TestSlots(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, slot := range []string{"red", "blue"} {
+		path, err := savestate.SlotPath(dir, slot)
+		if err != nil {
+			t.Errorf("unable to get slot path: %s", err)
+			return
+		}
+
+		if err := savestate.SavePokedex(path, testPokedex(slot+"-pokemon")); err != nil {
+			t.Errorf("unable to save slot %s: %s", slot, err)
+			return
+		}
+	}
+
+	slots, err := savestate.ListSlots(dir)
+	if err != nil {
+		t.Errorf("unable to list slots: %s", err)
+		return
+	}
+
+	if len(slots) != 2 || slots[0].Name != "blue" || slots[1].Name != "red" {
+		t.Errorf("expected slots blue and red, got %+v", slots)
+		return
+	}
+
+	if slots[0].Count != 1 || slots[0].Err != nil {
+		t.Errorf("expected slot blue to hold 1 pokemon, got %+v", slots[0])
+	}
+
+	if err := savestate.DeleteSlot(dir, "red"); err != nil {
+		t.Errorf("unable to delete slot: %s", err)
+		return
+	}
+
+	slots, err = savestate.ListSlots(dir)
+	if err != nil || len(slots) != 1 {
+		t.Errorf("expected a single slot after deleting, got %+v", slots)
+	}
+
+	if _, err := savestate.SlotPath(dir, "../escape"); err == nil {
+		t.Errorf("expected an invalid slot name to be rejected")
+	}
+}
+
+func TestIntegrity(t *testing.T) {
+	savestate.SetIntegrityKey([]byte("test key"))
+	t.Cleanup(func() { savestate.SetIntegrityKey(nil) })
+
+	path := filepath.Join(t.TempDir(), "save.json")
+	if err := savestate.SavePokedex(path, testPokedex("pikachu")); err != nil {
+		t.Errorf("unable to save: %s", err)
+		return
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Errorf("unable to read save: %s", err)
+		return
+	}
+
+	if _, report, err := savestate.DecodeSave(data); err != nil || !report.OK() {
+		t.Errorf("expected an untouched save to pass, got %+v, %v", report, err)
+		return
+	}
+
+	// a valid entry that was edited by hand
+	tampered := bytes.Replace(data, []byte(`"pikachu"`), []byte(`"mewtwo"`), 1)
+	if _, report, err := savestate.DecodeSave(tampered); err != nil || !report.ChecksumMismatch {
+		t.Errorf("expected an edited save to fail its checksum, got %+v, %v", report, err)
+		return
+	}
+
+	damaged := []byte(`{
+		"schema_version": 5,
+		"checksum": "",
+		"species": [
+			{"id": "abc", "name": "broken"},
+			{"id": 25, "name": "pikachu"},
+			{"id": 25, "name": "pikachu"},
+			{"id": 1, "name": "Not A Pokemon!"},
+			{"id": 4, "name": "charmander", "stats": [{"base_stat": 999}]}
+		],
+		"caught": [
+			{"id": "000001", "species": "pikachu"},
+			{"id": "000001", "species": "pikachu"},
+			{"id": "000002", "species": "charmander"},
+			{"id": "000003", "species": "pikachu", "level": 101},
+			{"id": "not hex", "species": "pikachu"}
+		]
+	}`)
+	if err := os.WriteFile(path, damaged, 0644); err != nil {
+		t.Errorf("unable to write damaged save: %s", err)
+		return
+	}
+
+	dex := pokedex.NewPokedex()
+	_, err = savestate.LoadPokedex(path, dex, savestate.LoadOptions{Mode: savestate.Replace})
+
+	var integrityErr *savestate.IntegrityError
+	if !errors.As(err, &integrityErr) {
+		t.Errorf("expected an integrity error, got %v", err)
+		return
+	}
+
+	expected := []string{"species 1", "species 3", "species 4", "species 5", "caught 2", "caught 3", "caught 4", "caught 5"}
+	problems := integrityErr.Report.Problems
+	if len(problems) != len(expected) {
+		t.Errorf("expected %d problems, got %+v", len(expected), problems)
+		return
+	}
+	for i, problem := range problems {
+		if fmt.Sprintf("%s %d", problem.List, problem.Position) != expected[i] {
+			t.Errorf("expected problem at %s, got %+v", expected[i], problem)
+		}
+	}
+
+	if len(dex.Caught()) != 0 {
+		t.Errorf("expected nothing to be loaded without repairing")
+		return
+	}
+
+	result, err := savestate.LoadPokedex(path, dex, savestate.LoadOptions{Mode: savestate.Replace, Repair: true})
+	if err != nil {
+		t.Errorf("unable to repair save: %s", err)
+		return
+	}
+
+	if result.Report.OK() || len(dex.Caught()) != 1 {
+		t.Errorf("expected only pikachu to be salvaged, got %d caught", len(dex.Caught()))
+	}
+
+	if !dex.IsDirty() {
+		t.Errorf("expected a repaired pokedex to need saving")
+	}
+}
+
+func TestSaveTeamAndSeen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.json")
+
+	team := []pokedex.TeamMember{
+		{Species: "pikachu", Level: 100, Moves: []string{}},
+		{Species: "eevee", Level: 100, Moves: []string{}},
+	}
+	team[0].Moves = []string{"volt-tackle"}
+
+	dex := testPokedex("pikachu")
+	dex.SetTeam(team)
+	dex.MarkSeen("pidgey", "rattata")
+	if err := savestate.SavePokedex(path, dex); err != nil {
+		t.Errorf("unable to save: %s", err)
+		return
+	}
+
+	loaded := pokedex.NewPokedex()
+	if _, err := savestate.LoadPokedex(path, loaded, savestate.LoadOptions{Mode: savestate.Merge}); err != nil {
+		t.Errorf("unable to load: %s", err)
+		return
+	}
+
+	if fmt.Sprintf("%+v", loaded.Team()) != fmt.Sprintf("%+v", team) {
+		t.Errorf("expected team %+v, got %+v", team, loaded.Team())
+	}
+	if seen := loaded.Seen(); len(seen) != 3 {
+		t.Errorf("expected pidgey, pikachu and rattata to be seen, got %v", seen)
+	}
+	if loaded.IsDirty() {
+		t.Errorf("expected no unsaved changes after loading the team")
+	}
+}
+
+func TestSaveBag(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.json")
+
+	dex := testPokedex("pikachu")
+	if err := dex.UseItem("poke-ball", 10); err != nil {
+		t.Errorf("unable to use the starter balls: %s", err)
+		return
+	}
+	dex.AddItem("great-ball", 2)
+	dex.Earn(500)
+	dex.MarkExplored("viridian-forest-area")
+	dex.SetLocation("viridian-forest-area")
+	if err := savestate.SavePokedex(path, dex); err != nil {
+		t.Errorf("unable to save: %s", err)
+		return
+	}
+
+	loaded := pokedex.NewPokedex()
+	if _, err := savestate.LoadPokedex(path, loaded, savestate.LoadOptions{Mode: savestate.Replace}); err != nil {
+		t.Errorf("unable to load: %s", err)
+		return
+	}
+
+	expected := "[{Name:great-ball Quantity:2} {Name:potion Quantity:3}]"
+	if actual := fmt.Sprintf("%+v", loaded.Bag()); actual != expected {
+		t.Errorf("expected bag %s, got %s", expected, actual)
+	}
+	if loaded.Location() != "viridian-forest-area" {
+		t.Errorf("expected to be in viridian-forest-area, got %s", loaded.Location())
+	}
+	if loaded.Money() != pokedex.StarterMoney+500 {
+		t.Errorf("expected $%d, got $%d", pokedex.StarterMoney+500, loaded.Money())
+	}
+	if explored := loaded.Explored(); len(explored) != 1 || explored[0] != "viridian-forest-area" {
+		t.Errorf("expected viridian-forest-area to be explored, got %v", explored)
+	}
+
+	// merging keeps the larger amount of each item and of money
+	merged := testPokedex("eevee")
+	result, err := savestate.LoadPokedex(path, merged, savestate.LoadOptions{Mode: savestate.Merge})
+	if err != nil {
+		t.Errorf("unable to merge: %s", err)
+		return
+	}
+	if merged.ItemCount("poke-ball") != 10 || merged.ItemCount("great-ball") != 2 || merged.ItemCount("potion") != 3 {
+		t.Errorf("expected the bags to be merged, got %+v", merged.Bag())
+	}
+	if merged.Money() != pokedex.StarterMoney+500 || !result.MoneyFromSave {
+		t.Errorf("expected the money of the save, got $%d", merged.Money())
+	}
+	expectedFromSave := "[{Name:great-ball Quantity:2}]"
+	if actual := fmt.Sprintf("%+v", result.ItemsFromSave); actual != expectedFromSave {
+		t.Errorf("expected %s to be taken from the save, got %s", expectedFromSave, actual)
+	}
+	if len(merged.Explored()) != 1 {
+		t.Errorf("expected explored areas to be merged, got %v", merged.Explored())
+	}
+
+	damaged := []byte(`{
+		"schema_version": 6,
+		"checksum": "",
+		"species": [],
+		"caught": [],
+		"bag": [
+			{"name": "poke-ball", "quantity": 5},
+			{"name": "great-ball", "quantity": 0},
+			{"name": "poke-ball", "quantity": 1},
+			{"name": "Ultra Ball", "quantity": 1},
+			{"name": "potion", "quantity": "lots"}
+		]
+	}`)
+
+	decoded, report, err := savestate.DecodeSave(damaged)
+	if err != nil {
+		t.Errorf("unable to decode save: %s", err)
+		return
+	}
+
+	expectedProblems := []string{"bag 2", "bag 3", "bag 4", "bag 5"}
+	if len(report.Problems) != len(expectedProblems) {
+		t.Errorf("expected %d problems, got %+v", len(expectedProblems), report.Problems)
+		return
+	}
+	for i, problem := range report.Problems {
+		if fmt.Sprintf("%s %d", problem.List, problem.Position) != expectedProblems[i] {
+			t.Errorf("expected problem at %s, got %+v", expectedProblems[i], problem)
+		}
+	}
+	if len(decoded.Bag) != 1 || decoded.Bag[0].Quantity != 5 {
+		t.Errorf("expected only the first poke ball entry to be kept, got %+v", decoded.Bag)
+	}
+}
+
+func TestSaveLayout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.json")
+
+	dex := pokedex.NewPokedex()
+	var IDs []string
+	for i := 0; i < 8; i++ {
+		caught := dex.Add(pokeapi.PokemonInfo{ID: 16, Name: "pidgey"}, pokedex.CaughtPokemon{
+			CaughtAt: time.Date(2025, 1, 1+i, 0, 0, 0, 0, time.UTC),
+		})
+		IDs = append(IDs, caught.ID)
+	}
+	if err := dex.Swap(IDs[0], IDs[7]); err != nil {
+		t.Errorf("unable to swap: %s", err)
+		return
+	}
+	if err := savestate.SavePokedex(path, dex); err != nil {
+		t.Errorf("unable to save: %s", err)
+		return
+	}
+
+	loaded := pokedex.NewPokedex()
+	if _, err := savestate.LoadPokedex(path, loaded, savestate.LoadOptions{Mode: savestate.Replace}); err != nil {
+		t.Errorf("unable to load: %s", err)
+		return
+	}
+
+	expectedParty, expectedBoxes := dex.Layout()
+	party, boxes := loaded.Layout()
+	if fmt.Sprint(party, boxes) != fmt.Sprint(expectedParty, expectedBoxes) {
+		t.Errorf("expected layout %v %v, got %v %v", expectedParty, expectedBoxes, party, boxes)
+		return
+	}
+	if loaded.IsDirty() {
+		t.Errorf("expected no unsaved changes after loading the layout")
+	}
+
+	// the pokedex keeps its own party when merging, and pokemon only in the save are put away
+	merged := testPokedex("eevee")
+	if _, err := savestate.LoadPokedex(path, merged, savestate.LoadOptions{Mode: savestate.Merge}); err != nil {
+		t.Errorf("unable to merge: %s", err)
+		return
+	}
+	party = nil
+	for _, individual := range merged.Party() {
+		party = append(party, individual.Species)
+	}
+	if len(party) != pokedex.PartySize || party[0] != "eevee" {
+		t.Errorf("expected eevee to lead a full party, got %v", party)
+	}
+	if !merged.IsDirty() {
+		t.Errorf("expected a merged layout to need saving")
+	}
+}
+
+func TestSaveWithoutPokemon(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.json")
+
+	// a new trainer has nothing to save
+	dex := pokedex.NewPokedex()
+	if err := savestate.SavePokedex(path, dex); err == nil {
+		t.Errorf("expected an error saving a new trainer")
+		return
+	}
+
+	if err := dex.Buy("great-ball", 1, 600); err != nil {
+		t.Errorf("unable to buy: %s", err)
+		return
+	}
+	if err := savestate.SavePokedex(path, dex); err != nil {
+		t.Errorf("expected a trainer who only shopped to be saved, got %s", err)
+		return
+	}
+
+	loaded := pokedex.NewPokedex()
+	if _, err := savestate.LoadPokedex(path, loaded, savestate.LoadOptions{Mode: savestate.Replace}); err != nil {
+		t.Errorf("unable to load: %s", err)
+		return
+	}
+	if loaded.ItemCount("great-ball") != 1 || loaded.Money() != pokedex.StarterMoney-600 {
+		t.Errorf("expected the great ball and money to be loaded, got %+v and $%d", loaded.Bag(), loaded.Money())
+		return
+	}
+
+	travelled := pokedex.NewPokedex()
+	travelled.SetLocation("viridian-forest-area")
+	if err := savestate.SavePokedex(filepath.Join(t.TempDir(), "save.json"), travelled); err != nil {
+		t.Errorf("expected a trainer who only travelled to be saved, got %s", err)
+	}
+}
@@ -0,0 +1,138 @@
+package savestate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	paths "github.com/nicholasss/pokedexcli/internal/paths"
+)
+
+// the slot used when none is given
+const DefaultSlot = "default"
+
+const slotExtension = ".json"
+
+// A summary of a save slot, without loading it.
+type SlotInfo struct {
+	Name     string
+	SaveTime time.Time
+	Count    int
+	// the slot failed its integrity checks
+	Damaged bool
+	// set when the slot could not be read
+	Err error
+}
+
+// returns the path of the save file for a slot in the folder
+func SlotPath(dir, slot string) (string, error) {
+	if !paths.ValidName(slot) {
+		return "", fmt.Errorf("invalid slot name '%s', use lowercase letters, numbers, '-' and '_'", slot)
+	}
+
+	return filepath.Join(dir, slot+slotExtension), nil
+}
+
+// returns every slot in the folder, sorted by name
+func ListSlots(dir string) ([]SlotInfo, error) {
+	mux.Lock()
+	defer mux.Unlock()
+
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return []SlotInfo{}, nil
+	} else if err != nil {
+		return []SlotInfo{}, err
+	}
+
+	slots := []SlotInfo{}
+	for _, entry := range entries {
+		name, isSave := strings.CutSuffix(entry.Name(), slotExtension)
+		if entry.IsDir() || !isSave {
+			continue
+		}
+
+		info := SlotInfo{Name: name}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			info.Err = err
+			slots = append(slots, info)
+			continue
+		}
+
+		save, report, err := decodeSave(data, nil)
+		if err != nil {
+			info.Err = err
+			slots = append(slots, info)
+			continue
+		}
+
+		info.SaveTime = save.SaveTime
+		info.Count = len(save.Caught)
+		info.Damaged = !report.OK()
+		slots = append(slots, info)
+	}
+
+	sort.Slice(slots, func(i, j int) bool {
+		return slots[i].Name < slots[j].Name
+	})
+
+	return slots, nil
+}
+
+// removes the save file for a slot.
+// a backup is kept first, so that the slot can still be restored
+func DeleteSlot(dir, slot string) error {
+	mux.Lock()
+	defer mux.Unlock()
+
+	path, err := SlotPath(dir, slot)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return errors.New("there is no save in slot '" + slot + "'")
+	}
+
+	if err := backupSave(path); err != nil {
+		return fmt.Errorf("unable to back up slot: %w", err)
+	}
+
+	return os.Remove(path)
+}
+
+// copies a save from before there were slots into the default slot,
+// only when the default slot does not exist yet.
+// the old file is left in place, returns whether it was copied
+func AdoptLegacySave(legacyPath, dir string) (bool, error) {
+	mux.Lock()
+	defer mux.Unlock()
+
+	path, err := SlotPath(dir, DefaultSlot)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(legacyPath)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	if err := writeAtomic(path, data); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
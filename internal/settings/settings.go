@@ -0,0 +1,70 @@
+// settings is an internal package
+// It provides the user's preferences, which are kept on disk between sessions
+package settings
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// The preferences that can be changed from the REPL.
+type Settings struct {
+	// language code used for names and descriptions, empty shows the API slugs
+	Language string `json:"language"`
+	// either "metric" or "imperial"
+	Units string `json:"units"`
+	// either "ansi", "ascii" or "off"
+	SpriteMode string `json:"sprite_mode"`
+	// saves after every successful catch
+	AutosaveOnCatch bool `json:"autosave_on_catch"`
+	// a duration such as "5m" between saves, empty when not saving on a timer
+	AutosaveInterval string `json:"autosave_interval"`
+}
+
+var mux sync.Mutex
+
+// returns the settings used when nothing has been saved yet
+func Default() Settings {
+	return Settings{
+		Language:         "",
+		Units:            "metric",
+		SpriteMode:       "ansi",
+		AutosaveOnCatch:  false,
+		AutosaveInterval: "",
+	}
+}
+
+// reads the settings at the path, a missing file gives the defaults
+func Load(path string) (Settings, error) {
+	mux.Lock()
+	defer mux.Unlock()
+
+	loaded := Default()
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return loaded, nil
+	} else if err != nil {
+		return loaded, err
+	}
+
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return Default(), err
+	}
+
+	return loaded, nil
+}
+
+// writes the settings to the path
+func Save(path string, current Settings) error {
+	mux.Lock()
+	defer mux.Unlock()
+
+	data, err := json.MarshalIndent(current, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,505 @@
+// showdown is an internal package
+// It provides reading and writing teams in the Pokemon Showdown paste format
+package showdown
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	pokedex "github.com/nicholasss/pokedexcli/internal/pokedex"
+)
+
+// =========
+// Constants
+// =========
+
+// the most moves a Pokemon can know
+const MaxMoves = 4
+
+// the most Pokemon on a team
+const MaxTeamSize = 6
+
+const (
+	maxEV      = 252
+	maxTotalEV = 510
+	maxIV      = 31
+	maxLevel   = 100
+)
+
+// the stats in the order Showdown writes them, with their labels
+var statLabels = []string{"HP", "Atk", "Def", "SpA", "SpD", "Spe"}
+
+// names of pokemon with punctuation that is lost in the PokeAPI name, by PokeAPI name.
+// every other name is its words capitalized
+var pokemonNames = map[string]string{
+	"mr-mime":   "Mr. Mime",
+	"mr-rime":   "Mr. Rime",
+	"mime-jr":   "Mime Jr.",
+	"ho-oh":     "Ho-Oh",
+	"porygon-z": "Porygon-Z",
+	"farfetchd": "Farfetch'd",
+	"sirfetchd": "Sirfetch'd",
+	"type-null": "Type: Null",
+	"flabebe":   "Flabébé",
+	"jangmo-o":  "Jangmo-o",
+	"hakamo-o":  "Hakamo-o",
+	"kommo-o":   "Kommo-o",
+	"nidoran-f": "Nidoran-F",
+	"nidoran-m": "Nidoran-M",
+	"wo-chien":  "Wo-Chien",
+	"chien-pao": "Chien-Pao",
+	"ting-lu":   "Ting-Lu",
+	"chi-yu":    "Chi-Yu",
+}
+
+// names of moves, abilities and items with punctuation that is lost in the PokeAPI name, by PokeAPI name
+var displayNames = map[string]string{
+	// moves
+	"u-turn":          "U-turn",
+	"v-create":        "V-create",
+	"x-scissor":       "X-Scissor",
+	"double-edge":     "Double-Edge",
+	"self-destruct":   "Self-Destruct",
+	"soft-boiled":     "Soft-Boiled",
+	"will-o-wisp":     "Will-O-Wisp",
+	"freeze-dry":      "Freeze-Dry",
+	"lock-on":         "Lock-On",
+	"mud-slap":        "Mud-Slap",
+	"wake-up-slap":    "Wake-Up Slap",
+	"power-up-punch":  "Power-Up Punch",
+	"baby-doll-eyes":  "Baby-Doll Eyes",
+	"topsy-turvy":     "Topsy-Turvy",
+	"trick-or-treat":  "Trick-or-Treat",
+	"multi-attack":    "Multi-Attack",
+	"kings-shield":    "King's Shield",
+	"forests-curse":   "Forest's Curse",
+	"lands-wrath":     "Land's Wrath",
+	"natures-madness": "Nature's Madness",
+	// abilities and items
+	"soul-heart":     "Soul-Heart",
+	"never-melt-ice": "Never-Melt Ice",
+	"kings-rock":     "King's Rock",
+}
+
+// a nickname followed by the species in brackets
+var nicknamed = regexp.MustCompile(`^(.*\S)\s+\(([^()]+)\)$`)
+
+// =====
+// Types
+// =====
+
+// A value for each stat, used for both EVs and IVs.
+type Stats = pokedex.TeamStats
+
+// A single Pokemon on a team, the same type the pokedex keeps the team in.
+// names are kept as PokeAPI names, such as "volt-tackle", and written back as display names
+type Set = pokedex.TeamMember
+
+// Returned by Parse for a line that is not in the Showdown format.
+type ParseError struct {
+	Line   int
+	Text   string
+	Reason string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d '%s': %s", e.Line, e.Text, e.Reason)
+}
+
+// ===============
+// Stats Functions
+// ===============
+
+// returns the stat with the Showdown label, such as "SpA"
+func statField(s *Stats, label string) *int {
+	switch strings.ToLower(label) {
+	case "hp":
+		return &s.HP
+	case "atk":
+		return &s.Attack
+	case "def":
+		return &s.Defense
+	case "spa":
+		return &s.SpecialAttack
+	case "spd":
+		return &s.SpecialDefense
+	case "spe":
+		return &s.Speed
+	}
+
+	return nil
+}
+
+// reads a list of stats such as "252 Atk / 4 SpD" on top of the given stats
+func parseStats(text string, stats Stats) (Stats, error) {
+	for _, part := range strings.Split(text, "/") {
+		fields := strings.Fields(part)
+		if len(fields) != 2 {
+			return stats, fmt.Errorf("'%s' is not a value and a stat", strings.TrimSpace(part))
+		}
+
+		value, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return stats, fmt.Errorf("'%s' is not a number", fields[0])
+		}
+
+		field := statField(&stats, fields[1])
+		if field == nil {
+			return stats, fmt.Errorf("'%s' is not a stat", fields[1])
+		}
+		*field = value
+	}
+
+	return stats, nil
+}
+
+// writes the stats that differ from the default, such as "252 Atk / 4 SpD"
+func formatStats(stats Stats, defaultValue int) string {
+	var parts []string
+	for _, label := range statLabels {
+		value := *statField(&stats, label)
+		if value != defaultValue {
+			parts = append(parts, fmt.Sprintf("%d %s", value, label))
+		}
+	}
+
+	return strings.Join(parts, " / ")
+}
+
+// =============
+// Set Functions
+// =============
+
+// returns a set with the values Showdown uses when a line is left out
+func NewSet(species string) Set {
+	return Set{
+		Species: species,
+		Level:   maxLevel,
+		IVs:     Stats{HP: maxIV, Attack: maxIV, Defense: maxIV, SpecialAttack: maxIV, SpecialDefense: maxIV, Speed: maxIV},
+		Moves:   []string{},
+	}
+}
+
+// checks the values of a set that do not need the PokeAPI, such as EV limits.
+// every problem found is returned
+func Validate(s Set) []string {
+	var problems []string
+
+	if s.Species == "" {
+		problems = append(problems, "no species")
+	}
+	if s.Level < 1 || s.Level > maxLevel {
+		problems = append(problems, fmt.Sprintf("level %d is not between 1 and %d", s.Level, maxLevel))
+	}
+	if s.Gender != "" && s.Gender != "M" && s.Gender != "F" {
+		problems = append(problems, fmt.Sprintf("gender '%s' is not M or F", s.Gender))
+	}
+	if len(s.Moves) > MaxMoves {
+		problems = append(problems, fmt.Sprintf("%d moves, at most %d can be known", len(s.Moves), MaxMoves))
+	}
+
+	for _, label := range statLabels {
+		ev := *statField(&s.EVs, label)
+		if ev < 0 || ev > maxEV {
+			problems = append(problems, fmt.Sprintf("%s EVs of %d are not between 0 and %d", label, ev, maxEV))
+		}
+
+		iv := *statField(&s.IVs, label)
+		if iv < 0 || iv > maxIV {
+			problems = append(problems, fmt.Sprintf("%s IVs of %d are not between 0 and %d", label, iv, maxIV))
+		}
+	}
+	if s.EVs.Total() > maxTotalEV {
+		problems = append(problems, fmt.Sprintf("%d EVs in total, at most %d are allowed", s.EVs.Total(), maxTotalEV))
+	}
+
+	return problems
+}
+
+// ===============
+// Paste Functions
+// ===============
+
+// reads every set in a Showdown paste, sets are separated by empty lines
+func Parse(r io.Reader) ([]Set, error) {
+	var sets []Set
+	var current *Set
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" {
+			if current != nil {
+				sets = append(sets, *current)
+				current = nil
+			}
+			continue
+		}
+
+		// the first line of a set names the Pokemon
+		if current == nil {
+			// team headers from the Showdown teambuilder are not a set
+			if strings.HasPrefix(line, "===") {
+				continue
+			}
+
+			set, err := parseHeader(line)
+			if err != nil {
+				return nil, &ParseError{Line: lineNumber, Text: line, Reason: err.Error()}
+			}
+			current = &set
+			continue
+		}
+
+		if err := parseLine(current, line); err != nil {
+			return nil, &ParseError{Line: lineNumber, Text: line, Reason: err.Error()}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if current != nil {
+		sets = append(sets, *current)
+	}
+
+	return sets, nil
+}
+
+// reads a line such as "Sparky (Pikachu) (M) @ Light Ball"
+func parseHeader(line string) (Set, error) {
+	var item string
+	if before, after, found := strings.Cut(line, " @ "); found {
+		line = strings.TrimSpace(before)
+		item = strings.TrimSpace(after)
+	}
+
+	var gender string
+	for _, suffix := range []string{"M", "F"} {
+		if strings.HasSuffix(line, " ("+suffix+")") {
+			gender = suffix
+			line = strings.TrimSpace(strings.TrimSuffix(line, " ("+suffix+")"))
+		}
+	}
+
+	nickname := ""
+	species := line
+	if match := nicknamed.FindStringSubmatch(line); match != nil {
+		nickname = match[1]
+		species = match[2]
+	}
+
+	if Slug(species) == "" {
+		return Set{}, fmt.Errorf("no species")
+	}
+
+	species, form := splitForm(species)
+	set := NewSet(Slug(species))
+	set.Form = Slug(form)
+	set.Nickname = nickname
+	set.Gender = gender
+	set.Item = Slug(item)
+
+	return set, nil
+}
+
+// splits a species such as "Landorus-Therian" into the species and its form, "Therian".
+// names with a dash of their own, such as "Ho-Oh", are not a form
+func splitForm(name string) (string, string) {
+	for _, speciesName := range pokemonNames {
+		if !strings.Contains(speciesName, "-") {
+			continue
+		}
+
+		if strings.EqualFold(name, speciesName) {
+			return name, ""
+		}
+		if len(name) > len(speciesName) && strings.EqualFold(name[:len(speciesName)+1], speciesName+"-") {
+			return name[:len(speciesName)], name[len(speciesName)+1:]
+		}
+	}
+
+	species, form, _ := strings.Cut(name, "-")
+	return species, form
+}
+
+// reads any line after the first one of a set into it
+func parseLine(set *Set, line string) error {
+	if move, found := strings.CutPrefix(line, "-"); found {
+		set.Moves = append(set.Moves, Slug(move))
+		return nil
+	}
+
+	if nature, found := strings.CutSuffix(line, " Nature"); found {
+		set.Nature = Slug(nature)
+		return nil
+	}
+
+	label, value, found := strings.Cut(line, ":")
+	if !found {
+		set.Other = append(set.Other, line)
+		return nil
+	}
+	value = strings.TrimSpace(value)
+
+	switch strings.ToLower(strings.TrimSpace(label)) {
+	case "ability":
+		set.Ability = Slug(value)
+	case "level":
+		level, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("'%s' is not a level", value)
+		}
+		set.Level = level
+	case "shiny":
+		set.Shiny = strings.EqualFold(value, "yes")
+	case "evs":
+		evs, err := parseStats(value, Stats{})
+		if err != nil {
+			return err
+		}
+		set.EVs = evs
+	case "ivs":
+		ivs, err := parseStats(value, set.IVs)
+		if err != nil {
+			return err
+		}
+		set.IVs = ivs
+	default:
+		set.Other = append(set.Other, line)
+	}
+
+	return nil
+}
+
+// writes the sets as a Showdown paste, leaving out lines that have their default value
+func Format(w io.Writer, sets []Set) error {
+	for i, set := range sets {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+
+		if _, err := io.WriteString(w, formatSet(set)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func formatSet(set Set) string {
+	var b strings.Builder
+
+	if set.Nickname != "" {
+		fmt.Fprintf(&b, "%s (%s)", set.Nickname, SpeciesName(set))
+	} else {
+		b.WriteString(SpeciesName(set))
+	}
+	if set.Gender != "" {
+		fmt.Fprintf(&b, " (%s)", set.Gender)
+	}
+	if set.Item != "" {
+		fmt.Fprintf(&b, " @ %s", DisplayName(set.Item))
+	}
+	b.WriteString("\n")
+
+	if set.Ability != "" {
+		fmt.Fprintf(&b, "Ability: %s\n", DisplayName(set.Ability))
+	}
+	if set.Level != maxLevel {
+		fmt.Fprintf(&b, "Level: %d\n", set.Level)
+	}
+	if set.Shiny {
+		b.WriteString("Shiny: Yes\n")
+	}
+	for _, line := range set.Other {
+		fmt.Fprintf(&b, "%s\n", line)
+	}
+	if evs := formatStats(set.EVs, 0); evs != "" {
+		fmt.Fprintf(&b, "EVs: %s\n", evs)
+	}
+	if set.Nature != "" {
+		fmt.Fprintf(&b, "%s Nature\n", DisplayName(set.Nature))
+	}
+	if ivs := formatStats(set.IVs, maxIV); ivs != "" {
+		fmt.Fprintf(&b, "IVs: %s\n", ivs)
+	}
+	for _, move := range set.Moves {
+		fmt.Fprintf(&b, "- %s\n", DisplayName(move))
+	}
+
+	return b.String()
+}
+
+// ==============
+// Name Functions
+// ==============
+
+// turns a Showdown name such as "Mr. Mime" or "Volt Tackle" into a PokeAPI name
+func Slug(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '_':
+			b.WriteRune('-')
+		case r == 'é':
+			b.WriteRune('e')
+		}
+	}
+
+	// spaces around removed characters, such as in "Mr. Mime", leave extra dashes
+	slug := b.String()
+	for strings.Contains(slug, "--") {
+		slug = strings.ReplaceAll(slug, "--", "-")
+	}
+
+	return strings.Trim(slug, "-")
+}
+
+// turns a PokeAPI name such as "volt-tackle" into a name for display, "Volt Tackle".
+// names such as "mr-mime" get back their punctuation, "Mr. Mime"
+func DisplayName(slug string) string {
+	if name, ok := pokemonNames[slug]; ok {
+		return name
+	}
+	if name, ok := displayNames[slug]; ok {
+		return name
+	}
+
+	words := strings.Split(slug, "-")
+	for i, word := range words {
+		if word != "" {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// returns the species of a set as Showdown writes it, followed by its form with a dash,
+// such as "Landorus-Therian" or "Necrozma-Dusk-Mane"
+func SpeciesName(set Set) string {
+	if set.Form == "" {
+		return DisplayName(set.Species)
+	}
+
+	words := strings.Split(set.Form, "-")
+	for i, word := range words {
+		if word != "" {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+
+	return DisplayName(set.Species) + "-" + strings.Join(words, "-")
+}
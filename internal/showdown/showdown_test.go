@@ -0,0 +1,225 @@
+package showdown_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	showdown "github.com/nicholasss/pokedexcli/internal/showdown"
+)
+
+const paste = `=== [gen9] Team ===
+
+Sparky (Pikachu) (M) @ Light Ball
+Ability: Static
+Level: 50
+Shiny: Yes
+Tera Type: Electric
+EVs: 252 Atk / 4 SpD / 252 Spe
+Jolly Nature
+IVs: 0 SpA
+- Volt Tackle
+- Iron Tail
+
+Mr. Mime @ Leftovers
+Ability: Filter
+Timid Nature
+- Psychic
+`
+
+func TestParse(t *testing.T) {
+	sets, err := showdown.Parse(strings.NewReader(paste))
+	if err != nil {
+		t.Errorf("unable to parse paste: %s", err)
+		return
+	}
+
+	if len(sets) != 2 {
+		t.Errorf("expected 2 sets, got %d", len(sets))
+		return
+	}
+
+	pikachu := sets[0]
+	if pikachu.Species != "pikachu" || pikachu.Nickname != "Sparky" || pikachu.Gender != "M" || pikachu.Item != "light-ball" {
+		t.Errorf("unexpected first line of set: %+v", pikachu)
+	}
+	if pikachu.Level != 50 || !pikachu.Shiny || pikachu.Nature != "jolly" || pikachu.Ability != "static" {
+		t.Errorf("unexpected details of set: %+v", pikachu)
+	}
+	if pikachu.EVs.Attack != 252 || pikachu.EVs.SpecialDefense != 4 || pikachu.EVs.HP != 0 {
+		t.Errorf("unexpected EVs: %+v", pikachu.EVs)
+	}
+	if pikachu.IVs.SpecialAttack != 0 || pikachu.IVs.Speed != 31 {
+		t.Errorf("unexpected IVs: %+v", pikachu.IVs)
+	}
+	if strings.Join(pikachu.Moves, " ") != "volt-tackle iron-tail" {
+		t.Errorf("unexpected moves: %v", pikachu.Moves)
+	}
+
+	if sets[1].Species != "mr-mime" || sets[1].Level != 100 {
+		t.Errorf("unexpected second set: %+v", sets[1])
+	}
+
+	// writing the sets and reading them again gives the same sets
+	var written strings.Builder
+	if err := showdown.Format(&written, sets); err != nil {
+		t.Errorf("unable to format sets: %s", err)
+		return
+	}
+
+	reread, err := showdown.Parse(strings.NewReader(written.String()))
+	if err != nil {
+		t.Errorf("unable to parse formatted sets: %s", err)
+		return
+	}
+	if fmt.Sprintf("%+v", reread) != fmt.Sprintf("%+v", sets) {
+		t.Errorf("expected sets to round trip, wrote:\n%s", written.String())
+	}
+}
+
+func TestFormRoundTrip(t *testing.T) {
+	cases := []struct {
+		input           string
+		expectedSpecies string
+		expectedForm    string
+	}{
+		{input: "Landorus-Therian @ Leftovers\n- Earthquake\n", expectedSpecies: "landorus", expectedForm: "therian"},
+		{input: "Landorus\n- Earthquake\n", expectedSpecies: "landorus", expectedForm: ""},
+		{input: "Necrozma-Dusk-Mane\n", expectedSpecies: "necrozma", expectedForm: "dusk-mane"},
+		{input: "Mr. Mime-Galar\n", expectedSpecies: "mr-mime", expectedForm: "galar"},
+		{input: "Ho-Oh\n", expectedSpecies: "ho-oh", expectedForm: ""},
+		{input: "Kommo-o-Totem\n", expectedSpecies: "kommo-o", expectedForm: "totem"},
+		{input: "Tapu Koko\n", expectedSpecies: "tapu-koko", expectedForm: ""},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			sets, err := showdown.Parse(strings.NewReader(c.input))
+			if err != nil || len(sets) != 1 {
+				t.Errorf("unable to parse set: %v", err)
+				return
+			}
+			if sets[0].Species != c.expectedSpecies || sets[0].Form != c.expectedForm {
+				t.Errorf("expected species '%s' and form '%s', got '%s' and '%s'",
+					c.expectedSpecies, c.expectedForm, sets[0].Species, sets[0].Form)
+				return
+			}
+
+			// the set is exported as Showdown writes it
+			var written strings.Builder
+			if err := showdown.Format(&written, sets); err != nil {
+				t.Errorf("unable to format set: %s", err)
+				return
+			}
+			if written.String() != c.input {
+				t.Errorf("expected '%s', got '%s'", c.input, written.String())
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []struct {
+		input        string
+		expectedLine int
+	}{
+		{input: "Pikachu\nLevel: fifty\n", expectedLine: 2},
+		{input: "Pikachu\n\nEevee\nEVs: 252 Attack\n", expectedLine: 4},
+		{input: "Eevee\nIVs: 0\n", expectedLine: 2},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			_, err := showdown.Parse(strings.NewReader(c.input))
+
+			parseErr, ok := err.(*showdown.ParseError)
+			if !ok {
+				t.Errorf("expected a parse error, got: %v", err)
+				return
+			}
+			if parseErr.Line != c.expectedLine {
+				t.Errorf("expected an error on line %d, got line %d", c.expectedLine, parseErr.Line)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := showdown.NewSet("eevee")
+	valid.Moves = []string{"tackle"}
+
+	tooManyEVs := showdown.NewSet("eevee")
+	tooManyEVs.EVs = showdown.Stats{HP: 252, Attack: 252, Speed: 252}
+
+	tooManyMoves := showdown.NewSet("eevee")
+	tooManyMoves.Moves = []string{"tackle", "growl", "bite", "swift", "dig"}
+	tooManyMoves.Level = 0
+
+	cases := []struct {
+		set      showdown.Set
+		expected int
+	}{
+		{set: valid, expected: 0},
+		{set: tooManyEVs, expected: 1},
+		{set: tooManyMoves, expected: 2},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			problems := showdown.Validate(c.set)
+			if len(problems) != c.expected {
+				t.Errorf("expected %d problems, got %v", c.expected, problems)
+			}
+		})
+	}
+}
+
+func TestSlug(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{input: "Volt Tackle", expected: "volt-tackle"},
+		{input: "Mr. Mime", expected: "mr-mime"},
+		{input: "Farfetch’d", expected: "farfetchd"},
+		{input: "Rotom-Wash", expected: "rotom-wash"},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			actual := showdown.Slug(c.input)
+			if actual != c.expected {
+				t.Errorf("expected '%s', got '%s'", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestDisplayName(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{input: "volt-tackle", expected: "Volt Tackle"},
+		{input: "mr-mime", expected: "Mr. Mime"},
+		{input: "ho-oh", expected: "Ho-Oh"},
+		{input: "farfetchd", expected: "Farfetch'd"},
+		{input: "flabebe", expected: "Flabébé"},
+		{input: "u-turn", expected: "U-turn"},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			actual := showdown.DisplayName(c.input)
+			if actual != c.expected {
+				t.Errorf("expected '%s', got '%s'", c.expected, actual)
+				return
+			}
+
+			// the name reads back as the same PokeAPI name
+			if slug := showdown.Slug(actual); slug != c.input {
+				t.Errorf("expected '%s' to read back as '%s', got '%s'", actual, c.input, slug)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+// sprite is an internal package
+// It provides rendering of Pokemon sprites as text for the terminal
+package sprite
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// =========
+// Constants
+// =========
+
+// How a sprite is drawn in the terminal.
+type Mode string
+
+const (
+	// 24-bit colour using half blocks, two pixels for every character
+	ANSI Mode = "ansi"
+	// plain characters picked by brightness, for terminals without colour
+	ASCII Mode = "ascii"
+	// sprites are not shown
+	Off Mode = "off"
+)
+
+const (
+	upperHalf = "▀"
+	lowerHalf = "▄"
+	reset     = "\x1b[0m"
+)
+
+// characters from darkest to lightest
+const asciiRamp = "@%#*+=-:. "
+
+// pixels with less alpha than this are treated as transparent
+const alphaThreshold = 128
+
+// ==================
+// Decoding Functions
+// ==================
+
+// decodes the raw bytes of a PNG sprite
+func Decode(data []byte) (image.Image, error) {
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode sprite: %w", err)
+	}
+
+	return img, nil
+}
+
+// ================
+// Render Functions
+// ================
+
+// writes the image using the given mode.
+// the image is first cropped to its visible pixels,
+// as the API sprites have a wide transparent border
+func Render(w io.Writer, img image.Image, mode Mode) error {
+	if mode == Off {
+		return nil
+	}
+
+	bounds := visibleBounds(img)
+	if bounds.Empty() {
+		return nil
+	}
+
+	var b strings.Builder
+
+	// each line of text covers two rows of pixels
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += 2 {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			top := pixelAt(img, x, y)
+			bottom := pixelAt(img, x, y+1)
+
+			if mode == ASCII {
+				b.WriteByte(asciiCell(top, bottom))
+			} else {
+				b.WriteString(ansiCell(top, bottom))
+			}
+		}
+
+		b.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// returns a half block coloured for the pair of pixels
+func ansiCell(top, bottom color.NRGBA) string {
+	topVisible := top.A >= alphaThreshold
+	bottomVisible := bottom.A >= alphaThreshold
+
+	switch {
+	case topVisible && bottomVisible:
+		return fmt.Sprintf("\x1b[38;2;%d;%d;%dm\x1b[48;2;%d;%d;%dm%s%s",
+			top.R, top.G, top.B, bottom.R, bottom.G, bottom.B, upperHalf, reset)
+	case topVisible:
+		return fmt.Sprintf("\x1b[38;2;%d;%d;%dm%s%s", top.R, top.G, top.B, upperHalf, reset)
+	case bottomVisible:
+		return fmt.Sprintf("\x1b[38;2;%d;%d;%dm%s%s", bottom.R, bottom.G, bottom.B, lowerHalf, reset)
+	default:
+		return " "
+	}
+}
+
+// returns a character for the average brightness of the visible pixels
+func asciiCell(top, bottom color.NRGBA) byte {
+	var total, count int
+	for _, pixel := range []color.NRGBA{top, bottom} {
+		if pixel.A < alphaThreshold {
+			continue
+		}
+		total += luminance(pixel)
+		count++
+	}
+
+	if count == 0 {
+		return ' '
+	}
+
+	index := (total / count) * (len(asciiRamp) - 1) / 256
+	return asciiRamp[index]
+}
+
+// =================
+// Utility Functions
+// =================
+
+// returns the brightness of a pixel from 0 to 255
+func luminance(pixel color.NRGBA) int {
+	return (299*int(pixel.R) + 587*int(pixel.G) + 114*int(pixel.B)) / 1000
+}
+
+// returns the pixel without premultiplied alpha,
+// pixels outside of the image are transparent
+func pixelAt(img image.Image, x, y int) color.NRGBA {
+	if !(image.Point{x, y}).In(img.Bounds()) {
+		return color.NRGBA{}
+	}
+
+	return color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+}
+
+// returns the smallest rectangle holding every visible pixel
+func visibleBounds(img image.Image) image.Rectangle {
+	var visible image.Rectangle
+	bounds := img.Bounds()
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if pixelAt(img, x, y).A < alphaThreshold {
+				continue
+			}
+			visible = visible.Union(image.Rect(x, y, x+1, y+1))
+		}
+	}
+
+	return visible
+}
@@ -0,0 +1,90 @@
+package sprite_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	sprite "github.com/nicholasss/pokedexcli/internal/sprite"
+)
+
+// builds a 4x4 image with a transparent border around a 2x2 square,
+// red on the top row and blue on the bottom row
+func testImage() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	img.Set(1, 1, color.NRGBA{R: 255, A: 255})
+	img.Set(2, 1, color.NRGBA{R: 255, A: 255})
+	img.Set(1, 2, color.NRGBA{B: 255, A: 255})
+	img.Set(2, 2, color.NRGBA{B: 255, A: 255})
+	return img
+}
+
+func TestRenderANSI(t *testing.T) {
+	cell := "\x1b[38;2;255;0;0m\x1b[48;2;0;0;255m▀\x1b[0m"
+	expected := cell + cell + "\n"
+
+	var actual bytes.Buffer
+	if err := sprite.Render(&actual, testImage(), sprite.ANSI); err != nil {
+		t.Errorf("unable to render: %s", err)
+		return
+	}
+
+	if actual.String() != expected {
+		t.Errorf("expected %q, got %q", expected, actual.String())
+	}
+}
+
+func TestRenderASCII(t *testing.T) {
+	img := testImage()
+
+	// a single visible pixel in the bottom half of a line
+	img.Set(1, 3, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+
+	expected := "%%\n. \n"
+
+	var actual bytes.Buffer
+	if err := sprite.Render(&actual, img, sprite.ASCII); err != nil {
+		t.Errorf("unable to render: %s", err)
+		return
+	}
+
+	if actual.String() != expected {
+		t.Errorf("expected %q, got %q", expected, actual.String())
+	}
+}
+
+func TestRenderOff(t *testing.T) {
+	var actual bytes.Buffer
+	if err := sprite.Render(&actual, testImage(), sprite.Off); err != nil {
+		t.Errorf("unable to render: %s", err)
+		return
+	}
+
+	if actual.Len() != 0 {
+		t.Errorf("expected nothing to be rendered, got %q", actual.String())
+	}
+}
+
+func TestDecode(t *testing.T) {
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, testImage()); err != nil {
+		t.Errorf("unable to encode: %s", err)
+		return
+	}
+
+	img, err := sprite.Decode(encoded.Bytes())
+	if err != nil {
+		t.Errorf("unable to decode: %s", err)
+		return
+	}
+
+	if img.Bounds() != image.Rect(0, 0, 4, 4) {
+		t.Errorf("expected 4x4 image, got %v", img.Bounds())
+	}
+
+	if _, err := sprite.Decode([]byte("not a png")); err == nil {
+		t.Errorf("expected error decoding invalid data")
+	}
+}
@@ -2,15 +2,33 @@ package main
 
 import (
 	"bufio"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"text/tabwriter"
 	"time"
 
+	display "github.com/nicholasss/pokedexcli/internal/display"
+	export "github.com/nicholasss/pokedexcli/internal/export"
+	importer "github.com/nicholasss/pokedexcli/internal/importer"
+	nameindex "github.com/nicholasss/pokedexcli/internal/nameindex"
+	paths "github.com/nicholasss/pokedexcli/internal/paths"
 	pokeapi "github.com/nicholasss/pokedexcli/internal/pokeapi"
 	pokecache "github.com/nicholasss/pokedexcli/internal/pokecache"
 	pokedex "github.com/nicholasss/pokedexcli/internal/pokedex"
 	savestate "github.com/nicholasss/pokedexcli/internal/savestate"
+	settings "github.com/nicholasss/pokedexcli/internal/settings"
+	showdown "github.com/nicholasss/pokedexcli/internal/showdown"
+	sprite "github.com/nicholasss/pokedexcli/internal/sprite"
 )
 
 // =====
@@ -19,15 +37,43 @@ import (
 type cliCommand struct {
 	name        string
 	description string
-	callback    func(*config, string) error
+	callback    func(*config, ...string) error
+	// arguments are passed as typed instead of lowercased, such as file paths
+	keepCase bool
 }
 
 type config struct {
+	autosave *time.Ticker
 	cache    *pokecache.Cache
-	mapNURL  string
-	mapPURL  string
-	pokedex  *pokedex.Pokedex
-	savePath string
+	input    <-chan string
+	// whether a signal arrived while a command was waiting for an answer
+	interrupted bool
+	mapNURL     string
+	mapPURL     string
+	names       *nameindex.Index
+	pokedex     *pokedex.Pokedex
+	slot        string
+	slotDir     string
+	// whether the slot was loaded or saved this session
+	slotInUse    bool
+	settings     settings.Settings
+	settingsPath string
+	// interrupts and terminations, handled between commands
+	signals <-chan os.Signal
+	// the randomness and time of catches, seeded with --seed for a repeatable session
+	source *pokedex.Source
+}
+
+// A wild pokemon that balls can be thrown at.
+type wildPokemon struct {
+	pokemon pokeapi.PokemonInfo
+	species pokeapi.PokemonSpecies
+	// zero when it is not known
+	level     int
+	hpPercent int
+	status    pokedex.Status
+	// the area it was found in
+	location string
 }
 
 // =====================
@@ -35,14 +81,57 @@ type config struct {
 // =====================
 var validCommands map[string]cliCommand
 
+// where automatic saves go when they would replace a slot not used this session
+const autosaveSlot = "autosave"
+
 func init() {
 
 	validCommands = map[string]cliCommand{
+		"autosave": {
+			name:        "autosave",
+			description: "Sets when to save automatically: off, catch, an interval such as 5m, or both",
+			callback:    commandAutosave,
+		},
+		"bag": {
+			name:        "bag",
+			description: "Lists the Poke Balls and items in your bag, and your money",
+			callback:    commandBag,
+		},
+		"buy": {
+			name:        "buy",
+			description: "Buys items at the shop: buy <item> [quantity]",
+			callback:    commandBuy,
+		},
+		"box": {
+			name:        "box",
+			description: "Lists the Pokemon in a box of the PC: box [number]",
+			callback:    commandBox,
+		},
 		"catch": {
 			name:        "catch",
-			description: "Attempts to catch a given Pokemon",
+			description: "Attempts to catch a given Pokemon, with --ball, --hp and --status",
 			callback:    commandCatch,
 		},
+		"delete-slot": {
+			name:        "delete-slot",
+			description: "Deletes a save slot, a backup of it is kept",
+			callback:    commandDeleteSlot,
+		},
+		"deposit": {
+			name:        "deposit",
+			description: "Sends a Pokemon from your party to the PC: deposit <id>",
+			callback:    commandDeposit,
+		},
+		"diff": {
+			name:        "diff",
+			description: "Shows what differs between your Pokedex and a save slot",
+			callback:    commandDiff,
+		},
+		"encounter": {
+			name:        "encounter",
+			description: "Looks for a wild Pokemon in the area you are in, use --method surf or old-rod and --version to choose how",
+			callback:    commandEncounter,
+		},
 		"exit": {
 			name:        "exit",
 			description: "Exit the Pokedex",
@@ -50,22 +139,39 @@ func init() {
 		},
 		"explore": {
 			name:        "explore",
-			description: "Lists Pokemon that live in a given area",
+			description: "Lists Pokemon that live in a given area, or in the area you are in and searches it for items",
 			callback:    commandExplore,
 		},
+		"export": {
+			name:        "export",
+			description: "Exports the Pokedex to a file: export <csv|json|markdown|yaml> <file>",
+			callback:    commandExport,
+			keepCase:    true,
+		},
 		"help": {
 			name:        "help",
 			description: "Displays a help message",
 			callback:    commandHelp,
 		},
+		"import": {
+			name:        "import",
+			description: "Imports Pokemon from a CSV file, a list of names or IDs, or ranges such as 1-151",
+			callback:    commandImport,
+			keepCase:    true,
+		},
 		"inspect": {
 			name:        "inspect",
-			description: "Provides info for a given Pokemon",
+			description: "Provides info for a given Pokemon, use --shiny or --back for other sprites",
 			callback:    commandInspect,
 		},
+		"lang": {
+			name:        "lang",
+			description: "Sets the language used for names and descriptions",
+			callback:    commandLang,
+		},
 		"load": {
 			name:        "load",
-			description: "Loads Pokedex from disk",
+			description: "Loads Pokedex from a slot, use replace or merge to choose how, --repair to skip bad entries",
 			callback:    commandLoad,
 		},
 		"map": {
@@ -78,16 +184,98 @@ func init() {
 			description: "Lists previous map areas",
 			callback:    commandMapB,
 		},
+		"nickname": {
+			name:        "nickname",
+			description: "Gives a caught Pokemon a nickname: nickname <id> [name], leave out the name to remove it",
+			callback:    commandNickname,
+			keepCase:    true,
+		},
+		"party": {
+			name:        "party",
+			description: "Lists the Pokemon in your party, new catches go to the PC when it is full",
+			callback:    commandParty,
+		},
 		"pokedex": {
 			name:        "pokedex",
-			description: "Lists all caught Pokemon",
+			description: "Lists caught Pokemon, filter with --type fire, --gen 1 or --min-stat attack=100, order with --sort id|name|bst|caught-at and --limit",
 			callback:    commandPokedex,
 		},
+		"progress": {
+			name:        "progress",
+			description: "Shows how many Pokemon you have seen and caught, by generation or regional Pokedex",
+			callback:    commandProgress,
+		},
+		"refresh": {
+			name:        "refresh",
+			description: "Updates every caught Pokemon with the latest data from the PokeAPI",
+			callback:    commandRefresh,
+		},
+		"restore": {
+			name:        "restore",
+			description: "Lists backups of a slot, or restores a numbered backup",
+			callback:    commandRestore,
+		},
 		"save": {
 			name:        "save",
-			description: "Saves Pokedex to disk",
+			description: "Saves Pokedex to disk, to the given slot or the current one",
 			callback:    commandSave,
 		},
+		"sell": {
+			name:        "sell",
+			description: "Sells items from your bag for half their price: sell <item> [quantity]",
+			callback:    commandSell,
+		},
+		"shop": {
+			name:        "shop",
+			description: "Lists the items for sale and their prices",
+			callback:    commandShop,
+		},
+		"sprites": {
+			name:        "sprites",
+			description: "Sets how sprites are drawn: ansi, ascii or off",
+			callback:    commandSprites,
+		},
+		"slots": {
+			name:        "slots",
+			description: "Lists the save slots of this profile",
+			callback:    commandSlots,
+		},
+		"swap": {
+			name:        "swap",
+			description: "Swaps the places of two caught Pokemon, such as one in your party and one in a box: swap <id> <id>",
+			callback:    commandSwap,
+		},
+		"team": {
+			name:        "team",
+			description: "Shows your team, use 'team import <file>' or 'team export showdown [file]' for Showdown pastes",
+			callback:    commandTeam,
+			keepCase:    true,
+		},
+		"travel": {
+			name:        "travel",
+			description: "Travels to a given area, Pokemon can only be caught where you are",
+			callback:    commandTravel,
+		},
+		"units": {
+			name:        "units",
+			description: "Sets metric or imperial units for height and weight",
+			callback:    commandUnits,
+		},
+		"walk": {
+			name:        "walk",
+			description: "Walks through the grass of the area you are in until a wild Pokemon appears",
+			callback:    commandEncounter,
+		},
+		"where": {
+			name:        "where",
+			description: "Lists where a given Pokemon can be found",
+			callback:    commandWhere,
+		},
+		"withdraw": {
+			name:        "withdraw",
+			description: "Moves a Pokemon from the PC to your party: withdraw <id>",
+			callback:    commandWithdraw,
+		},
 	}
 
 }
@@ -101,6 +289,49 @@ func cleanInput(text string) []string {
 	return words
 }
 
+// splits arguments into positional ones and flags.
+// a flag is written as "--name value", or as "--name" alone for the names given as switches
+func parseArgs(args []string, switches ...string) ([]string, map[string]string) {
+	var positional []string
+	flags := make(map[string]string)
+
+	for i := 0; i < len(args); i++ {
+		flagName, isFlag := strings.CutPrefix(args[i], "--")
+		if !isFlag {
+			positional = append(positional, args[i])
+			continue
+		}
+
+		if slices.Contains(switches, flagName) || i+1 >= len(args) {
+			flags[flagName] = ""
+			continue
+		}
+
+		flags[flagName] = args[i+1]
+		i++
+	}
+
+	return positional, flags
+}
+
+// splits arguments such as "great ball 5" into an item and a quantity,
+// the quantity is 1 when it is left out
+func parseItemArgs(args []string) (string, int, error) {
+	quantity := 1
+	if len(args) > 1 {
+		if number, err := strconv.Atoi(args[len(args)-1]); err == nil {
+			quantity = number
+			args = args[:len(args)-1]
+		}
+	}
+
+	if quantity < 1 || quantity > pokedex.MaxQuantity {
+		return "", 0, fmt.Errorf("'%d' is not a quantity from 1 to %d", quantity, pokedex.MaxQuantity)
+	}
+
+	return pokedex.ParseItem(strings.Join(args, " ")), quantity, nil
+}
+
 func requestThroughCache(URL string, cfg *config) ([]byte, error) {
 	reqData, inCache := cfg.cache.Get(URL)
 	// fmt.Println(" %%% Looking at:", URL)
@@ -114,275 +345,2289 @@ func requestThroughCache(URL string, cfg *config) ([]byte, error) {
 	return pokeapi.RequestGETBody(URL)
 }
 
-// =================
-// Command Functions
-// =================
-func commandCatch(cfg *config, name string) error {
-	if name == "" {
-		fmt.Println("Please provide the name of a Pokemon to catch.")
-		return nil
-	}
+// returns the address of a named resource, such as the pokemon "pikachu"
+func resourceURL(kind nameindex.Kind, name string) string {
+	return pokeapi.BaseURL + string(kind) + "/" + name + "/"
+}
 
-	URL := pokeapi.PokemonInfoURL + name + "/"
+// requests a named resource through the cache, decoded by the unmarshal function of its type
+func requestResource[T any](cfg *config, kind nameindex.Kind, name string, unmarshal func([]byte) (T, error)) (T, error) {
+	URL := resourceURL(kind, name)
 
 	data, err := requestThroughCache(URL, cfg)
 	if err != nil {
-		return fmt.Errorf("unable to request through cache: %w", err)
+		var empty T
+		return empty, fmt.Errorf("unable to request through cache: %w", err)
 	}
 
 	cfg.cache.Add(URL, data)
 
-	pokemon, err := pokeapi.UnmarshalPokemonInfo(data)
+	return unmarshal(data)
+}
+
+// finds the ball by name, listing the balls to choose from when it is not one
+func parseBallArg(name string) (pokedex.Ball, bool) {
+	ball, err := pokedex.ParseBall(name)
+	if err != nil {
+		var balls []string
+		for _, ball := range pokedex.Balls {
+			balls = append(balls, strings.TrimSuffix(string(ball), "-ball"))
+		}
+		fmt.Printf("'%s' is not a ball, use one of: %s\n", name, strings.Join(balls, ", "))
+		return "", false
+	}
+
+	return ball, true
+}
+
+// requests a pokemon and its species, for throwing balls at
+func requestWildPokemon(cfg *config, name string) (wildPokemon, error) {
+	pokemon, err := requestResource(cfg, nameindex.Pokemon, name, pokeapi.UnmarshalPokemonInfo)
+	if err != nil {
+		return wildPokemon{}, err
+	}
+
+	// the capture rate and gender rate are only on the species
+	species, err := requestResource(cfg, nameindex.PokemonSpecies, pokemon.Species.Name, pokeapi.UnmarshalPokemonSpecies)
 	if err != nil {
-		return fmt.Errorf("unable to unmarshal pokemon info: %w", err)
+		return wildPokemon{}, fmt.Errorf("unable to request species '%s': %w", pokemon.Species.Name, err)
+	}
+
+	return wildPokemon{
+		pokemon:   pokemon,
+		species:   species,
+		hpPercent: 100,
+	}, nil
+}
+
+// throws a ball from the bag at a wild pokemon on a turn of the encounter,
+// adding the pokemon to the pokedex when it is caught
+func throwBall(cfg *config, wild wildPokemon, ball pokedex.Ball, turn int) (bool, error) {
+	pokemon := wild.pokemon
+
+	// the ball is used up whether or not the pokemon is caught
+	if err := cfg.pokedex.UseItem(string(ball), 1); err != nil {
+		fmt.Println("Unable to throw:", err)
+		return false, nil
+	}
+
+	var types []string
+	for _, pType := range pokemon.TypeList {
+		types = append(types, pType.PType.Name)
 	}
 
-	caught := pokedex.AttemptCatch(pokemon)
+	caught := pokedex.AttemptCatch(cfg.source, pokemon, pokedex.CatchAttempt{
+		CaptureRate:   wild.species.CaptureRate,
+		Ball:          ball,
+		HPPercent:     wild.hpPercent,
+		Status:        wild.status,
+		Turn:          turn,
+		ThrownAt:      cfg.source.Now(),
+		Types:         types,
+		InCave:        pokedex.IsCave(wild.location),
+		AlreadyCaught: len(cfg.pokedex.Individuals(pokemon.Name)) > 0,
+	})
 	if !caught {
-		return nil
+		// an escaped pokemon was still seen
+		cfg.pokedex.MarkSeen(pokemon.Name)
+		return false, nil
+	}
+
+	if err := showSprite(cfg, pokemon, false, false); err != nil {
+		fmt.Println("Unable to show sprite:", err)
 	}
 
 	// Add to pokedex if caught
-	cfg.pokedex.Add(name, pokemon)
+	individual := cfg.pokedex.Add(pokemon, pokedex.CaughtPokemon{
+		LocationArea: wild.location,
+		Ball:         string(ball),
+		Level:        wild.level,
+		Gender:       pokedex.RollGender(cfg.source, wild.species.GenderRate),
+	})
+	fmt.Printf("%s was added to your Pokedex as #%s.\n", pokemon.Name, individual.ID)
+	if place, found := cfg.pokedex.PlaceOf(individual.ID); found && !place.InParty() {
+		fmt.Printf("Your party is full, so it was sent to Box %d.\n", place.Box)
+	}
 
-	return nil
-}
+	reward := pokedex.CatchReward(wild.species.CaptureRate)
+	cfg.pokedex.Earn(reward)
+	fmt.Printf("You earned $%d.\n", reward)
 
-func commandExit(cfg *config, optional string) error {
-	fmt.Println("Closing the Pokedex... Goodbye!")
-	os.Exit(0)
+	if cfg.settings.AutosaveOnCatch {
+		autosave(cfg)
+	}
 
-	return nil
+	return true, nil
 }
 
-func commandExplore(cfg *config, name string) error {
-	if name == "" {
-		fmt.Println("Please provide the name of an area to explore.")
-		return nil
+// reports whether the pokemon can be encountered in the location area
+func livesIn(locationInfo pokeapi.LocationInfo, name string) bool {
+	for _, encounter := range locationInfo.PokemonList {
+		if encounter.Pokemon.Name == name {
+			return true
+		}
 	}
+	return false
+}
 
-	// creating URL
-	URL := pokeapi.LocationAreaInfoURL + name + "/"
+// finds the species of the seen pokemon that are a form, such as "basculin-red-striped".
+// only the data of caught pokemon is kept in the pokedex, so the others are requested
+func seenForms(cfg *config, speciesNames []string) map[string]string {
+	known := make(map[string]bool, len(speciesNames))
+	for _, name := range speciesNames {
+		known[name] = true
+	}
+	caught, _ := cfg.pokedex.GetAll()
+	for _, name := range caught {
+		known[name] = true
+	}
 
-	// requesting through cache
-	data, err := requestThroughCache(URL, cfg)
-	if err != nil {
-		return fmt.Errorf("unable to request through cache: %w", err)
+	forms := make(map[string]string)
+	for _, name := range cfg.pokedex.Seen() {
+		if known[name] {
+			continue
+		}
+
+		pokemon, err := requestResource(cfg, nameindex.Pokemon, name, pokeapi.UnmarshalPokemonInfo)
+		if err != nil || pokemon.Species.Name == "" {
+			continue
+		}
+		forms[name] = pokemon.Species.Name
 	}
 
-	// TODO: check to ensure that cache doesnt do more work if replacing with same data
-	// adding back to cache
-	cfg.cache.Add(URL, data)
+	return forms
+}
 
-	locationInfo, err := pokeapi.UnmarshalLocationInfo(data)
+// returns the progress through a regional pokedex, named in the language setting
+func regionalProgress(cfg *config, name string, forms map[string]string) (pokedex.Progress, error) {
+	regional, err := requestResource(cfg, nameindex.Pokedex, name, pokeapi.UnmarshalRegionalPokedex)
 	if err != nil {
-		return err
+		return pokedex.Progress{}, err
 	}
 
-	// print out list of pokemon here
-	for _, pokemon := range locationInfo.PokemonList {
-		fmt.Println(pokemon.Pokemon.Name)
+	species := make([]string, 0, len(regional.PokemonEntries))
+	for _, entry := range regional.PokemonEntries {
+		species = append(species, entry.PokemonSpecies.Name)
 	}
 
-	return nil
+	return cfg.pokedex.Progress(localizedName(cfg, regional.Names, regional.Name), species, forms), nil
 }
 
-func commandHelp(cfg *config, optional string) error {
-	fmt.Printf("Welcome to the Pokedex!\nUsage:\n\n")
-	for _, ci := range validCommands {
-		fmt.Printf("%s: %s\n", ci.name, ci.description)
+// returns the name in the language setting, or the slug when there is no setting
+func localizedName(cfg *config, names []pokeapi.Name, slug string) string {
+	if cfg.settings.Language == "" {
+		return slug
 	}
+	return pokeapi.LocalizedName(names, cfg.settings.Language, slug)
+}
 
-	return nil
+// writes a row of progress for a table
+func writeProgress(writer io.Writer, progress pokedex.Progress) {
+	fmt.Fprintf(writer, "%s\t%d\t%d\t%d\t%.1f%%\n",
+		progress.Name, progress.Seen, progress.Caught, progress.Total, progress.Completion())
 }
 
-func commandInspect(cfg *config, name string) error {
-	if name == "" {
-		fmt.Println("Please provide the name of a Pokemon you have caught.")
-		return nil
+// returns the name of an area in the chosen language.
+// the slug is returned when no language is set or the area cannot be requested
+func localizedAreaName(cfg *config, slug string) string {
+	if cfg.settings.Language == "" {
+		return slug
 	}
 
-	pokemon, wasCaught := cfg.pokedex.Get(name)
-	if !wasCaught {
-		return nil
+	locationInfo, err := requestResource(cfg, nameindex.LocationArea, slug, pokeapi.UnmarshalLocationInfo)
+	if err != nil {
+		return slug
 	}
 
-	statList := pokemon.StatList
-	typeList := pokemon.TypeList
+	localized := pokeapi.LocalizedName(locationInfo.Names, cfg.settings.Language, slug)
+
+	// so that the localized name can be typed back in,
+	// an alias that fails to save only lasts for this session
+	cfg.names.AddAlias(nameindex.LocationArea, localized, slug)
+
+	return localized
+}
 
-	fmt.Printf("Name: %s\n", pokemon.Name)
-	fmt.Printf("Height: %d\n", pokemon.Height)
-	fmt.Printf("Weight: %d\n", pokemon.Weight)
+// the most names requested at once when naming a list
+const maxConcurrentNames = 8
 
-	fmt.Printf("Stats:\n")
-	for _, stat := range statList {
-		fmt.Printf("  -%s: %d\n", stat.Stat.Name, stat.BaseStat)
+// names every slug of a list in the chosen language, keeping their order.
+// the names are requested at the same time, so that a list takes about as long as one request
+func localizeAll(cfg *config, slugs []string, localize func(*config, string) string) []string {
+	names := slices.Clone(slugs)
+	if cfg.settings.Language == "" {
+		return names
 	}
 
-	fmt.Printf("Types:\n")
-	for _, pType := range typeList {
-		fmt.Printf("  -%s\n", pType.PType.Name)
+	var wg sync.WaitGroup
+	limit := make(chan struct{}, maxConcurrentNames)
+	for i, slug := range slugs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			limit <- struct{}{}
+			defer func() { <-limit }()
+
+			names[i] = localize(cfg, slug)
+		}()
 	}
+	wg.Wait()
 
-	return nil
+	return names
 }
 
-func commandLoad(cfg *config, optional string) error {
-	err := savestate.LoadPokedex(cfg.savePath, cfg.pokedex)
+// returns the name of a pokemon in the chosen language, using the names of its species.
+// the slug is returned when no language is set or the species cannot be requested
+func localizedPokemonName(cfg *config, slug, speciesName string) string {
+	if cfg.settings.Language == "" {
+		return slug
+	}
+
+	if speciesName == "" {
+		speciesName = slug
+	}
+
+	species, err := requestResource(cfg, nameindex.PokemonSpecies, speciesName, pokeapi.UnmarshalPokemonSpecies)
 	if err != nil {
-		return fmt.Errorf("unable to load save: %w", err)
+		return slug
 	}
 
-	return nil
+	localized := pokeapi.LocalizedName(species.Names, cfg.settings.Language, slug)
+	cfg.names.AddAlias(nameindex.Pokemon, localized, slug)
+
+	return localized
 }
 
-func commandMap(cfg *config, optional string) error {
-	// checking URL
+// draws the sprite of a pokemon using the chosen sprite mode
+func showSprite(cfg *config, pokemon pokeapi.PokemonInfo, shiny, back bool) error {
+	mode := sprite.Mode(cfg.settings.SpriteMode)
+	if mode == sprite.Off {
+		return nil
+	}
+
 	var URL string
-	if cfg.mapNURL == "null" {
-		URL = pokeapi.LocationAreaListURL
-	} else {
-		URL = cfg.mapNURL
+	switch {
+	case shiny && back:
+		URL = pokemon.Sprites.BackShiny
+	case shiny:
+		URL = pokemon.Sprites.FrontShiny
+	case back:
+		URL = pokemon.Sprites.BackDefault
+	default:
+		URL = pokemon.Sprites.FrontDefault
 	}
 
-	// check the cache
-	body, err := requestThroughCache(URL, cfg)
+	if URL == "" {
+		return errors.New("there is no sprite for this pokemon")
+	}
+
+	data, err := requestThroughCache(URL, cfg)
 	if err != nil {
-		return fmt.Errorf("unable to request data: %w", err)
+		return fmt.Errorf("unable to request through cache: %w", err)
 	}
 
-	// add to cache
-	cfg.cache.Add(URL, body)
+	cfg.cache.Add(URL, data)
 
-	locationList, err := pokeapi.UnmarshalLocationList(body)
+	img, err := sprite.Decode(data)
 	if err != nil {
-		return fmt.Errorf("unable to unmarshal json request: %w", err)
+		return err
 	}
 
-	for _, loc := range locationList.Results {
-		fmt.Println(loc.Name)
-	}
+	return sprite.Render(os.Stdout, img, mode)
+}
 
-	if locationList.Next != nil {
-		cfg.mapNURL = *locationList.Next
-	}
-	if locationList.Previous != nil {
-		cfg.mapPURL = *locationList.Previous
-	}
+// reads lines from the reader in the background,
+// the channel is closed when there is no more input
+func readLines(reader io.Reader) <-chan string {
+	lines := make(chan string)
 
-	return nil
+	go func() {
+		defer close(lines)
+
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+
+		if err := scanner.Err(); err != nil {
+			fmt.Printf("Error occured: %s\n", err)
+		}
+	}()
+
+	return lines
 }
 
-func commandMapB(cfg *config, optional string) error {
-	// checking for URL
-	var URL string
-	if cfg.mapPURL == "null" {
-		URL = pokeapi.LocationAreaListURL
-	} else {
-		URL = cfg.mapPURL
+// autosaves and quits after a signal,
+// called between commands so that a command is never saved halfway through
+func quitOnSignal(cfg *config) {
+	autosave(cfg)
+	if err := cfg.names.Flush(); err != nil {
+		fmt.Println("Unable to save the name index:", err)
 	}
+	fmt.Println("Closing the Pokedex... Goodbye!")
+	os.Exit(0)
+}
 
-	// check the cache
-	body, err := requestThroughCache(URL, cfg)
-	if err != nil {
-		return fmt.Errorf("unable to request body: %w", err)
+// waits for the next line of input, false when input has ended.
+// a signal also ends the wait, the command is then left to return before quitting
+func readLine(cfg *config) (string, bool) {
+	select {
+	case line, ok := <-cfg.input:
+		return line, ok
+	case <-cfg.signals:
+		fmt.Println()
+		cfg.interrupted = true
+		return "", false
 	}
+}
 
-	// add to cache
-	cfg.cache.Add(URL, body)
+// saves without being asked, after catches, on a timer and before quitting.
+// the current slot is only written to if it was loaded or saved this session,
+// or does not exist yet, so that an old save is not replaced by mistake.
+// otherwise the changes go to the autosave slot
+func autosave(cfg *config) {
+	if !cfg.pokedex.IsDirty() {
+		return
+	}
 
-	locationList, err := pokeapi.UnmarshalLocationList(body)
+	slot := cfg.slot
+	path, err := savestate.SlotPath(cfg.slotDir, slot)
 	if err != nil {
-		return fmt.Errorf("unable to unmarshal json request: %w", err)
+		fmt.Println("Unable to autosave:", err)
+		return
 	}
 
-	for _, loc := range locationList.Results {
-		fmt.Println(loc.Name)
+	if _, err := os.Stat(path); err == nil && !cfg.slotInUse {
+		slot = autosaveSlot
+		path, err = savestate.SlotPath(cfg.slotDir, slot)
+		if err != nil {
+			fmt.Println("Unable to autosave:", err)
+			return
+		}
 	}
 
-	if locationList.Next != nil {
-		cfg.mapNURL = *locationList.Next
+	if err := savestate.SavePokedex(path, cfg.pokedex); err != nil {
+		fmt.Println("Unable to autosave:", err)
+		return
 	}
-	if locationList.Previous != nil {
-		cfg.mapPURL = *locationList.Previous
+
+	fmt.Printf("Autosaved to slot '%s'.\n", slot)
+}
+
+// returns the channel of the autosave timer, nil when there is no timer
+func autosaveTimer(cfg *config) <-chan time.Time {
+	if cfg.autosave == nil {
+		return nil
 	}
 
-	return nil
+	return cfg.autosave.C
 }
 
-func commandPokedex(cfg *config, optional string) error {
-	pokemonList, namesInList := cfg.pokedex.GetAll()
-	if !namesInList {
-		fmt.Println("You have not caught any Pokemon yet!")
+// starts, stops or changes the autosave timer to match the settings
+func resetAutosaveTimer(cfg *config) error {
+	if cfg.autosave != nil {
+		cfg.autosave.Stop()
+		cfg.autosave = nil
+	}
+
+	if cfg.settings.AutosaveInterval == "" {
 		return nil
 	}
 
-	fmt.Println("Your Pokedex:")
-	for _, name := range pokemonList {
-		fmt.Printf("  -%s\n", name)
+	interval, err := time.ParseDuration(cfg.settings.AutosaveInterval)
+	if err != nil {
+		return err
 	}
 
+	cfg.autosave = time.NewTicker(interval)
 	return nil
 }
 
-func commandSave(cfg *config, optional string) error {
-	err := savestate.SavePokedex(cfg.savePath, cfg.pokedex)
+// prints what was found wrong with a save
+func printIntegrityReport(report savestate.IntegrityReport) {
+	if report.ChecksumMismatch {
+		fmt.Println("The save was changed outside of the Pokedex, or is damaged.")
+	}
+
+	if len(report.Problems) > 0 {
+		fmt.Printf("%d entries in the save are bad:\n", len(report.Problems))
+		for _, problem := range report.Problems {
+			name := problem.Name
+			if name == "" {
+				name = "(no name)"
+			}
+			fmt.Printf("  #%d %s: %s\n", problem.Position, name, problem.Reason)
+		}
+	}
+}
+
+// returns the given slot, or the current slot when none is given, and its path
+func chooseSlot(cfg *config, args []string) (string, string, error) {
+	slot := cfg.slot
+	if len(args) > 0 {
+		slot = args[0]
+	}
+
+	path, err := savestate.SlotPath(cfg.slotDir, slot)
 	if err != nil {
-		return fmt.Errorf("unable to save pokedex: %w", err)
+		return "", "", err
 	}
 
-	return nil
+	return slot, path, nil
 }
 
-// =============
-// Main Function
-// =============
-func main() {
+// reports whether the name exists without printing anything,
+// names are assumed to exist when the index is unavailable
+func knownName(cfg *config, kind nameindex.Kind, name string) bool {
+	_, err := cfg.names.Resolve(kind, name)
 
-	const interval = (10 * time.Minute)
-	const saveFilePath = "./save.json"
+	var notFound *nameindex.NotFoundError
+	return !errors.As(err, &notFound)
+}
 
-	// local variables struct
-	cfg := &config{
-		cache:    pokecache.NewCache(interval),
-		mapNURL:  "null",
-		mapPURL:  "null",
-		pokedex:  pokedex.NewPokedex(),
-		savePath: saveFilePath,
+// resolves a name or ID typed by the user to a known name,
+// printing suggestions when nothing matches
+func resolveName(cfg *config, kind nameindex.Kind, input string) (string, bool) {
+	name, err := cfg.names.Resolve(kind, input)
+
+	var notFound *nameindex.NotFoundError
+	if errors.As(err, &notFound) {
+		fmt.Printf("Unable to find %s '%s'.\n", kind, input)
+		if len(notFound.Suggestions) > 0 {
+			fmt.Printf("Did you mean: %s?\n", strings.Join(notFound.Suggestions, ", "))
+		}
+		return "", false
+	} else if err != nil {
+		// without an index the input is used as typed
+		return input, true
 	}
 
-	scanner := bufio.NewScanner(os.Stdin)
+	return name, true
+}
 
-	for {
-		fmt.Print("Pokedex > ") // prompt
+// resolves a name from a file, returning a problem instead of printing it.
+// names are kept as written when the index is unavailable
+func checkName(cfg *config, kind nameindex.Kind, input string) (string, string) {
+	name, err := cfg.names.Resolve(kind, input)
 
-		if ok := scanner.Scan(); !ok {
-			continue // no text provided
+	var notFound *nameindex.NotFoundError
+	if errors.As(err, &notFound) {
+		problem := fmt.Sprintf("unknown %s '%s'", kind, input)
+		if len(notFound.Suggestions) > 0 {
+			problem += fmt.Sprintf(", did you mean: %s?", strings.Join(notFound.Suggestions, ", "))
 		}
+		return "", problem
+	} else if err != nil {
+		return input, ""
+	}
 
-		if err := scanner.Err(); err != nil {
-			fmt.Printf("Error occured: %s\n", err)
+	return name, ""
+}
+
+// finds the species with the name and the pokemon it is by default, for names that are only a species
+func defaultVariety(cfg *config, name string) (string, string, bool) {
+	speciesName, problem := checkName(cfg, nameindex.PokemonSpecies, name)
+	if problem != "" {
+		return "", "", false
+	}
+
+	species, err := requestResource(cfg, nameindex.PokemonSpecies, speciesName, pokeapi.UnmarshalPokemonSpecies)
+	if err != nil {
+		return "", "", false
+	}
+
+	return speciesName, pokeapi.DefaultVariety(species), true
+}
+
+// checks a team member against the PokeAPI, replacing its names with the API names.
+// the ability and moves are only checked against the species when they can be requested
+func validateSet(cfg *config, set *showdown.Set) []string {
+	problems := showdown.Validate(*set)
+
+	pokemon, problem := checkName(cfg, nameindex.Pokemon, set.Pokemon())
+	if problem == "" {
+		// the species and form are kept apart, to be written back as "Landorus-Therian"
+		set.Species, set.Form = pokemon, ""
+		info, err := requestResource(cfg, nameindex.Pokemon, pokemon, pokeapi.UnmarshalPokemonInfo)
+		if err == nil && info.Species.Name != "" {
+			if form, found := strings.CutPrefix(pokemon, info.Species.Name+"-"); found {
+				set.Species, set.Form = info.Species.Name, form
+			}
+		}
+	} else if set.Form == "" {
+		// species such as landorus are only pokemon in one of their forms, landorus-incarnate.
+		// the species is kept without a form as it was given, the form is only used for checking
+		if speciesName, variety, ok := defaultVariety(cfg, set.Species); ok {
+			set.Species, pokemon, problem = speciesName, variety, ""
+		}
+	}
+	if problem != "" {
+		// nothing else can be checked against an unknown species
+		return append(problems, problem)
+	}
+
+	if set.Item != "" {
+		if set.Item, problem = checkName(cfg, nameindex.Item, set.Item); problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+	if set.Nature != "" {
+		if set.Nature, problem = checkName(cfg, nameindex.Nature, set.Nature); problem != "" {
+			problems = append(problems, problem)
 		}
+	}
 
-		args := cleanInput(scanner.Text())
-		command := args[0]
+	if set.Ability != "" {
+		set.Ability, problem = checkName(cfg, nameindex.Ability, set.Ability)
+		if problem != "" {
+			problems = append(problems, problem)
+		} else if ability, err := requestResource(cfg, nameindex.Ability, set.Ability, pokeapi.UnmarshalAbilityInfo); err == nil {
+			canHave := false
+			for _, holder := range ability.Pokemon {
+				if holder.Pokemon.Name == pokemon {
+					canHave = true
+					break
+				}
+			}
+			if !canHave {
+				problems = append(problems, fmt.Sprintf("%s cannot have the ability '%s'", pokemon, set.Ability))
+			}
+		}
+	}
 
-		var optional string
-		if len(args) > 1 {
-			optional = args[1]
-		} else {
-			optional = ""
+	for i, move := range set.Moves {
+		set.Moves[i], problem = checkName(cfg, nameindex.Move, move)
+		if problem != "" {
+			problems = append(problems, problem)
+			continue
 		}
 
-		validCommand, exists := validCommands[command]
-		if !exists {
-			fmt.Println("Unknown command")
+		moveInfo, err := requestResource(cfg, nameindex.Move, set.Moves[i], pokeapi.UnmarshalMoveInfo)
+		if err != nil {
 			continue
 		}
 
-		// pass in local variables struct, and optional argument
-		if err := validCommand.callback(cfg, optional); err != nil {
-			fmt.Println("Error in commands:", err)
+		canLearn := false
+		for _, learner := range moveInfo.LearnedByPokemon {
+			if learner.Name == pokemon {
+				canLearn = true
+				break
+			}
+		}
+		if !canLearn {
+			problems = append(problems, fmt.Sprintf("%s cannot learn the move '%s'", pokemon, set.Moves[i]))
+		}
+	}
+
+	return problems
+}
+
+// describes a caught pokemon in a single line, such as "Sparky (pikachu) #0a1b2c"
+func caughtLabel(individual pokedex.CaughtPokemon) string {
+	if individual.Nickname != "" {
+		return fmt.Sprintf("%s (%s) #%s", individual.Nickname, individual.Species, individual.ID)
+	}
+	return fmt.Sprintf("%s #%s", individual.Species, individual.ID)
+}
+
+// prints each caught pokemon of a species with what is known about it
+func printIndividuals(cfg *config, individuals []pokedex.CaughtPokemon) {
+	fmt.Printf("Caught (%d):\n", len(individuals))
+	for _, individual := range individuals {
+		details := []string{}
+		if individual.Level > 0 {
+			details = append(details, fmt.Sprintf("Lv. %d", individual.Level))
+		}
+		if individual.Gender != "" {
+			details = append(details, individual.Gender)
+		}
+		if individual.Ball != "" {
+			details = append(details, individual.Ball)
+		}
+		if individual.LocationArea != "" {
+			details = append(details, "at "+localizedAreaName(cfg, individual.LocationArea))
+		}
+
+		when := "caught"
+		if individual.Imported {
+			when = "imported"
+		}
+		details = append(details, fmt.Sprintf("%s %s", when, individual.CaughtAt.Local().Format(time.DateTime)))
+
+		fmt.Printf("  #%s %s: %s\n", individual.ID, individual.DisplayName(), strings.Join(details, ", "))
+	}
+}
+
+// prints the pokemon in the party or a box, numbered by their slot
+func printStorage(individuals []pokedex.CaughtPokemon) error {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for i, individual := range individuals {
+		level := ""
+		if individual.Level > 0 {
+			level = fmt.Sprintf("Lv. %d", individual.Level)
+		}
+		fmt.Fprintf(writer, "  %d\t%s\t%s\n", i+1, caughtLabel(individual), level)
+	}
+
+	return writer.Flush()
+}
+
+// =================
+// Command Functions
+// =================
+func commandAutosave(cfg *config, args ...string) error {
+	if len(args) == 0 {
+		switch {
+		case cfg.settings.AutosaveOnCatch && cfg.settings.AutosaveInterval != "":
+			fmt.Printf("Autosaving after every catch and every %s.\n", cfg.settings.AutosaveInterval)
+		case cfg.settings.AutosaveOnCatch:
+			fmt.Println("Autosaving after every catch.")
+		case cfg.settings.AutosaveInterval != "":
+			fmt.Printf("Autosaving every %s.\n", cfg.settings.AutosaveInterval)
+		default:
+			fmt.Println("Autosave is off.")
+		}
+		fmt.Println("Use 'autosave off', 'autosave catch', 'autosave 5m' or 'autosave catch 5m' to change it.")
+		return nil
+	}
+
+	onCatch := false
+	interval := ""
+	for _, arg := range args {
+		switch arg {
+		case "off":
+			continue
+		case "catch":
+			onCatch = true
+		default:
+			duration, err := time.ParseDuration(arg)
+			if err != nil || duration < time.Minute {
+				fmt.Printf("'%s' is not an interval of at least a minute, such as 5m.\n", arg)
+				return nil
+			}
+			interval = duration.String()
+		}
+	}
+
+	cfg.settings.AutosaveOnCatch = onCatch
+	cfg.settings.AutosaveInterval = interval
+	if err := settings.Save(cfg.settingsPath, cfg.settings); err != nil {
+		return fmt.Errorf("unable to save settings: %w", err)
+	}
+
+	if err := resetAutosaveTimer(cfg); err != nil {
+		return fmt.Errorf("unable to start the autosave timer: %w", err)
+	}
+
+	return commandAutosave(cfg)
+}
+
+func commandBag(cfg *config, args ...string) error {
+	fmt.Printf("Money: $%d\n", cfg.pokedex.Money())
+
+	items := cfg.pokedex.Bag()
+	if len(items) == 0 {
+		fmt.Println("Your bag is empty. Explore new areas to find items, or buy them at the shop.")
+		return nil
+	}
+
+	fmt.Println("Your bag:")
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	pocket := ""
+	for _, item := range items {
+		if pokedex.Pocket(item.Name) != pocket {
+			pocket = pokedex.Pocket(item.Name)
+			fmt.Fprintf(writer, "%s:\n", pocket)
+		}
+		fmt.Fprintf(writer, "  %s\tx%d\n", pokedex.ItemName(item.Name), item.Quantity)
+	}
+
+	return writer.Flush()
+}
+
+func commandBuy(cfg *config, args ...string) error {
+	if len(args) == 0 {
+		fmt.Println("Please provide an item to buy, such as 'buy great ball 5'.")
+		return nil
+	}
+
+	name, quantity, err := parseItemArgs(args)
+	if err != nil {
+		fmt.Println(err)
+		return nil
+	}
+
+	if !slices.Contains(pokedex.ShopItems, name) {
+		fmt.Printf("The shop does not sell %s. Use 'shop' to see what is for sale.\n", pokedex.ItemName(name))
+		return nil
+	}
+
+	item, err := requestResource(cfg, nameindex.Item, name, pokeapi.UnmarshalItemInfo)
+	if err != nil {
+		return fmt.Errorf("unable to request item '%s': %w", name, err)
+	}
+
+	if err := cfg.pokedex.Buy(name, quantity, item.Cost); err != nil {
+		fmt.Println("Unable to buy:", err)
+		return nil
+	}
+
+	fmt.Printf("Bought %s x%d for $%d. You have $%d left.\n",
+		pokedex.ItemName(name), quantity, item.Cost*quantity, cfg.pokedex.Money())
+	return nil
+}
+
+func commandBox(cfg *config, args ...string) error {
+	number := 1
+	if len(args) > 0 {
+		parsed, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Printf("'%s' is not a box number.\n", args[0])
+			return nil
+		}
+		number = parsed
+	}
+
+	individuals, err := cfg.pokedex.Box(number)
+	if err != nil {
+		fmt.Println("Unable to open box:", err)
+		return nil
+	}
+
+	fmt.Printf("Box %d of %d (%d/%d):\n", number, cfg.pokedex.BoxCount(), len(individuals), pokedex.BoxSize)
+	if len(individuals) == 0 {
+		fmt.Println("  This box is empty.")
+		return nil
+	}
+
+	return printStorage(individuals)
+}
+
+func commandCatch(cfg *config, args ...string) error {
+	args, flags := parseArgs(args)
+
+	name := strings.Join(args, " ")
+	if name == "" {
+		fmt.Println("Please provide the name of a Pokemon to catch.")
+		return nil
+	}
+
+	ball := pokedex.PokeBall
+	if ballName, ok := flags["ball"]; ok {
+		parsed, ok := parseBallArg(ballName)
+		if !ok {
+			return nil
+		}
+		ball = parsed
+	}
+
+	// such as --hp 25 for a pokemon with a quarter of its HP left
+	hpPercent := 100
+	if hp, ok := flags["hp"]; ok {
+		number, err := strconv.Atoi(strings.TrimSuffix(hp, "%"))
+		if err != nil || number < 1 || number > 100 {
+			fmt.Printf("'%s' is not a percentage of HP between 1 and 100.\n", hp)
+			return nil
+		}
+		hpPercent = number
+	}
+
+	status, err := pokedex.ParseStatus(flags["status"])
+	if err != nil {
+		var statuses []string
+		for _, status := range pokedex.Statuses {
+			statuses = append(statuses, string(status))
+		}
+		fmt.Printf("'%s' is not a status, use one of: %s\n", flags["status"], strings.Join(statuses, ", "))
+		return nil
+	}
+
+	if cfg.pokedex.ItemCount(string(ball)) == 0 {
+		fmt.Printf("You have no %ss left. Use 'bag' to see your items.\n", ball)
+		return nil
+	}
+
+	location := cfg.pokedex.Location()
+	if location == "" {
+		fmt.Println("You are not in any area yet. Use 'travel <area>' to go somewhere.")
+		return nil
+	}
+
+	name, found := resolveName(cfg, nameindex.Pokemon, name)
+	if !found {
+		return nil
+	}
+
+	// only pokemon that live in the area can be caught there
+	locationInfo, err := requestResource(cfg, nameindex.LocationArea, location, pokeapi.UnmarshalLocationInfo)
+	if err != nil {
+		return fmt.Errorf("unable to request area '%s': %w", location, err)
+	}
+	if !livesIn(locationInfo, name) {
+		fmt.Printf("%s does not live in %s. Use 'explore' to see what does, or 'where %s' to find it.\n",
+			name, localizedAreaName(cfg, location), name)
+		return nil
+	}
+
+	wild, err := requestWildPokemon(cfg, name)
+	if err != nil {
+		return err
+	}
+	wild.hpPercent = hpPercent
+	wild.status = status
+	wild.location = location
+
+	_, err = throwBall(cfg, wild, ball, 1)
+	return err
+}
+
+func commandDeleteSlot(cfg *config, args ...string) error {
+	if len(args) == 0 {
+		fmt.Println("Please provide the name of a slot to delete.")
+		return nil
+	}
+
+	slot := args[0]
+	if err := savestate.DeleteSlot(cfg.slotDir, slot); err != nil {
+		return fmt.Errorf("unable to delete slot: %w", err)
+	}
+
+	fmt.Printf("Deleted slot '%s'.\n", slot)
+	fmt.Printf("Use 'restore %s' to bring it back from its backups.\n", slot)
+	return nil
+}
+
+func commandDeposit(cfg *config, args ...string) error {
+	if len(args) == 0 {
+		fmt.Println("Please provide the ID of a Pokemon in your party, such as 'deposit 0a1b2c'.")
+		return nil
+	}
+
+	ID := strings.TrimPrefix(args[0], "#")
+	box, err := cfg.pokedex.Deposit(ID)
+	if err != nil {
+		fmt.Println("Unable to deposit:", err)
+		return nil
+	}
+
+	individual, _ := cfg.pokedex.Find(ID)
+	fmt.Printf("%s was sent to Box %d.\n", caughtLabel(individual), box)
+	return nil
+}
+
+func commandDiff(cfg *config, args ...string) error {
+	slot, path, err := chooseSlot(cfg, args)
+	if err != nil {
+		return err
+	}
+
+	save, report, err := savestate.ReadSave(path)
+	if err != nil {
+		return fmt.Errorf("unable to read save: %w", err)
+	}
+
+	if !report.OK() {
+		printIntegrityReport(report)
+		fmt.Println("Only the entries that are fine are compared.")
+	}
+
+	difference := cfg.pokedex.Diff(save.Caught)
+	if difference.Empty() {
+		fmt.Printf("Your Pokedex matches slot '%s'.\n", slot)
+		return nil
+	}
+
+	if len(difference.OnlyInPokedex) > 0 {
+		fmt.Printf("Only in your Pokedex (%d):\n", len(difference.OnlyInPokedex))
+		for _, individual := range difference.OnlyInPokedex {
+			fmt.Printf("  +%s\n", caughtLabel(individual))
+		}
+	}
+
+	if len(difference.OnlyInRecords) > 0 {
+		fmt.Printf("Only in slot '%s' (%d):\n", slot, len(difference.OnlyInRecords))
+		for _, individual := range difference.OnlyInRecords {
+			fmt.Printf("  -%s\n", caughtLabel(individual))
+		}
+	}
+
+	if len(difference.Conflicts) > 0 {
+		fmt.Printf("Different (%d):\n", len(difference.Conflicts))
+		for _, conflict := range difference.Conflicts {
+			changes := conflict.InPokedex.Changes(conflict.InRecords)
+			fmt.Printf("  ~%s: the %s differs\n", caughtLabel(conflict.InPokedex), strings.Join(changes, ", "))
+		}
+	}
+
+	return nil
+}
+
+func commandEncounter(cfg *config, args ...string) error {
+	_, flags := parseArgs(args)
+
+	location := cfg.pokedex.Location()
+	if location == "" {
+		fmt.Println("You are not in any area yet. Use 'travel <area>' to go somewhere.")
+		return nil
+	}
+
+	locationInfo, err := requestResource(cfg, nameindex.LocationArea, location, pokeapi.UnmarshalLocationInfo)
+	if err != nil {
+		return fmt.Errorf("unable to request area '%s': %w", location, err)
+	}
+
+	method := pokedex.ParseMethod(flags["method"])
+	slots := pokedex.EncounterSlots(locationInfo, method, flags["version"])
+	encounter, found := pokedex.RollEncounter(cfg.source, slots)
+	if !found {
+		methods := pokedex.EncounterMethods(locationInfo)
+		if len(methods) == 0 {
+			fmt.Printf("No wild Pokemon live in %s.\n", localizedAreaName(cfg, location))
+			return nil
+		}
+		how := method
+		if version, ok := flags["version"]; ok {
+			how += " in " + version
+		}
+		fmt.Printf("No wild Pokemon can be found by %s here, try --method with one of: %s\n", how, strings.Join(methods, ", "))
+		return nil
+	}
+
+	wild, err := requestWildPokemon(cfg, encounter.Pokemon)
+	if err != nil {
+		return err
+	}
+	wild.level = encounter.Level
+	wild.location = location
+
+	cfg.pokedex.MarkSeen(encounter.Pokemon)
+	if err := showSprite(cfg, wild.pokemon, false, false); err != nil {
+		fmt.Println("Unable to show sprite:", err)
+	}
+	fmt.Printf("A wild %s (Lv. %d) appeared!\n", localizedPokemonName(cfg, encounter.Pokemon, ""), encounter.Level)
+
+	// the encounter lasts until the pokemon is caught or the trainer runs
+	for turn := 1; ; {
+		fmt.Print("What will you do? (catch [ball]/run) ")
+
+		answer, ok := readLine(cfg)
+		if !ok {
+			return nil
+		}
+
+		fields := strings.Fields(strings.ToLower(answer))
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "run":
+			fmt.Println("Got away safely!")
+			return nil
+
+		case "catch", "throw":
+			ball := pokedex.PokeBall
+			if len(fields) > 1 {
+				parsed, ok := parseBallArg(strings.Join(fields[1:], " "))
+				if !ok {
+					continue
+				}
+				ball = parsed
+			}
+
+			if cfg.pokedex.ItemCount(string(ball)) == 0 {
+				fmt.Printf("You have no %ss left.\n", ball)
+				continue
+			}
+
+			caught, err := throwBall(cfg, wild, ball, turn)
+			if err != nil || caught {
+				return err
+			}
+			turn++
+
+		default:
+			fmt.Println("Please answer catch, catch with a ball such as 'catch great', or run.")
+		}
+	}
+}
+
+func commandExit(cfg *config, args ...string) error {
+	if cfg.pokedex.IsDirty() {
+		fmt.Print("You have unsaved changes. Save before exiting? (y/n) ")
+
+		answer, answered := readLine(cfg)
+		if !answered {
+			// nobody is left to answer, so keep the changes
+			fmt.Println()
+			autosave(cfg)
+		} else if strings.HasPrefix(strings.ToLower(strings.TrimSpace(answer)), "y") {
+			if err := commandSave(cfg); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Println("Closing the Pokedex... Goodbye!")
+	os.Exit(0)
+
+	return nil
+}
+
+func commandExplore(cfg *config, args ...string) error {
+	name := strings.Join(args, " ")
+
+	// without an area, the area the trainer is in is described
+	if name == "" {
+		name = cfg.pokedex.Location()
+		if name == "" {
+			fmt.Println("You are not in any area yet. Use 'travel <area>' to go somewhere, or 'explore <area>' to look at one.")
+			return nil
+		}
+		fmt.Printf("You are in %s.\n", localizedAreaName(cfg, name))
+	} else {
+		resolved, found := resolveName(cfg, nameindex.LocationArea, name)
+		if !found {
+			return nil
+		}
+		name = resolved
+	}
+
+	locationInfo, err := requestResource(cfg, nameindex.LocationArea, name, pokeapi.UnmarshalLocationInfo)
+	if err != nil {
+		return err
+	}
+
+	// print out list of pokemon here
+	var names []string
+	for _, pokemon := range locationInfo.PokemonList {
+		names = append(names, pokemon.Pokemon.Name)
+	}
+	localizedPokemon := func(cfg *config, slug string) string {
+		return localizedPokemonName(cfg, slug, "")
+	}
+	for _, name := range localizeAll(cfg, names, localizedPokemon) {
+		fmt.Println(name)
+	}
+
+	if added := cfg.pokedex.MarkSeen(names...); added > 0 {
+		fmt.Printf("%d Pokemon were seen for the first time.\n", added)
+	}
+
+	// an area looked at from elsewhere has nothing to find, travel there first
+	if name != cfg.pokedex.Location() {
+		fmt.Printf("Travel to %s with 'travel %s' to search it for items.\n", localizedAreaName(cfg, name), name)
+		return nil
+	}
+
+	// something is found the first time an area is explored
+	if cfg.pokedex.MarkExplored(name) {
+		item := pokedex.FindItem(cfg.source)
+		added := cfg.pokedex.AddItem(item.Name, item.Quantity)
+		cfg.pokedex.Earn(pokedex.ExploreReward)
+		fmt.Printf("You found %s x%d and $%d!\n", pokedex.ItemName(item.Name), item.Quantity, pokedex.ExploreReward)
+		if added < item.Quantity {
+			fmt.Printf("Your bag is full of %s, %d had to be left behind.\n", pokedex.ItemName(item.Name), item.Quantity-added)
+		}
+	}
+
+	return nil
+}
+
+func commandExport(cfg *config, args ...string) error {
+	if len(args) < 2 {
+		fmt.Println("Please provide a format and a file, such as 'export csv pokedex.csv'.")
+		fmt.Printf("Formats: %s\n", strings.Join(export.Formats(), ", "))
+		return nil
+	}
+
+	exporter, err := export.ForFormat(args[0])
+	if err != nil {
+		fmt.Println(err)
+		return nil
+	}
+
+	rows := export.Rows(cfg.pokedex.Species(), cfg.pokedex.Caught())
+	if len(rows) == 0 {
+		fmt.Println("You have not caught any Pokemon yet!")
+		return nil
+	}
+
+	path := strings.Join(args[1:], " ")
+
+	// a dash writes to the terminal instead of a file
+	if path == "-" {
+		return exporter.Export(os.Stdout, rows)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("unable to create export file: %w", err)
+	}
+	defer file.Close()
+
+	if err := exporter.Export(file, rows); err != nil {
+		return fmt.Errorf("unable to export pokedex: %w", err)
+	}
+
+	fmt.Printf("Exported %d Pokemon to %s.\n", len(rows), path)
+	return nil
+}
+
+func commandHelp(cfg *config, args ...string) error {
+	fmt.Printf("Welcome to the Pokedex!\nUsage:\n\n")
+	for _, ci := range validCommands {
+		fmt.Printf("%s: %s\n", ci.name, ci.description)
+	}
+
+	return nil
+}
+
+func commandImport(cfg *config, args ...string) error {
+	path := strings.Join(args, " ")
+	if path == "" {
+		fmt.Println("Please provide a file to import, such as 'import pokedex.csv'.")
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("unable to open import file: %w", err)
+	}
+	defer file.Close()
+
+	entries, problems, err := importer.Parse(file)
+	if err != nil {
+		return fmt.Errorf("unable to read import file: %w", err)
+	}
+
+	// each entry is checked against the name index before anything is requested
+	var names []string
+	lines := make(map[string]int)
+	for _, entry := range entries {
+		name, err := cfg.names.Resolve(nameindex.Pokemon, entry.Value)
+
+		var notFound *nameindex.NotFoundError
+		if errors.As(err, &notFound) {
+			reason := "unknown pokemon"
+			if len(notFound.Suggestions) > 0 {
+				reason += fmt.Sprintf(", did you mean: %s?", strings.Join(notFound.Suggestions, ", "))
+			}
+			problems = append(problems, importer.Problem{Line: entry.Line, Value: entry.Value, Reason: reason})
+			continue
+		} else if err != nil {
+			// without an index the entry is checked by requesting it
+			name = strings.ToLower(entry.Value)
+		}
+
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+			lines[name] = entry.Line
+		}
+	}
+
+	if len(names) > 0 {
+		fmt.Printf("Importing %d Pokemon...\n", len(names))
+	}
+
+	var pokemon []pokeapi.PokemonInfo
+	for _, name := range names {
+		pokemonStruct, err := requestResource(cfg, nameindex.Pokemon, name, pokeapi.UnmarshalPokemonInfo)
+		if err != nil {
+			problems = append(problems, importer.Problem{Line: lines[name], Value: name, Reason: err.Error()})
+			continue
+		}
+		pokemon = append(pokemon, pokemonStruct)
+	}
+
+	skipped := cfg.pokedex.Import(pokemon)
+
+	fmt.Printf("Imported %d Pokemon from %s.\n", len(pokemon)-len(skipped), path)
+	if len(skipped) > 0 {
+		fmt.Printf("Already in your Pokedex (%d): %s\n", len(skipped), strings.Join(skipped, ", "))
+	}
+
+	if len(problems) > 0 {
+		fmt.Printf("Unable to import %d entries:\n", len(problems))
+		for _, problem := range problems {
+			fmt.Printf("  line %d '%s': %s\n", problem.Line, problem.Value, problem.Reason)
+		}
+	}
+
+	return nil
+}
+
+func commandInspect(cfg *config, args ...string) error {
+	args, flags := parseArgs(args, "shiny", "back")
+	_, shiny := flags["shiny"]
+	_, back := flags["back"]
+
+	name := strings.Join(args, " ")
+	if name == "" {
+		fmt.Println("Please provide the name of a Pokemon you have caught.")
+		return nil
+	}
+
+	name, found := resolveName(cfg, nameindex.Pokemon, name)
+	if !found {
+		return nil
+	}
+
+	pokemon, wasCaught := cfg.pokedex.Get(name)
+	if !wasCaught {
+		return nil
+	}
+
+	speciesName := pokemon.Species.Name
+	if speciesName == "" {
+		speciesName = pokemon.Name
+	}
+
+	language := cfg.settings.Language
+	if language == "" {
+		language = pokeapi.FallbackLanguage
+	}
+
+	if err := showSprite(cfg, pokemon, shiny, back); err != nil {
+		fmt.Println("Unable to show sprite:", err)
+	}
+
+	// the description is left out when the species cannot be requested
+	var description string
+	species, err := requestResource(cfg, nameindex.PokemonSpecies, speciesName, pokeapi.UnmarshalPokemonSpecies)
+	if err == nil {
+		description = pokeapi.LocalizedFlavorText(species.FlavorTextEntries, language)
+	}
+
+	err = display.Inspect(os.Stdout, pokemon, display.Options{
+		Units:       display.Units(cfg.settings.Units),
+		DisplayName: localizedPokemonName(cfg, pokemon.Name, pokemon.Species.Name),
+		Description: description,
+	})
+	if err != nil {
+		return err
+	}
+
+	printIndividuals(cfg, cfg.pokedex.Individuals(name))
+	return nil
+}
+
+func commandLang(cfg *config, args ...string) error {
+	if len(args) == 0 {
+		if cfg.settings.Language == "" {
+			fmt.Println("No language is set, names are shown as they are in the API.")
+		} else {
+			fmt.Printf("The current language is '%s'.\n", cfg.settings.Language)
+		}
+		fmt.Println("Use 'lang <code>' to change it, or 'lang none' to go back to API names.")
+		return nil
+	}
+
+	language := ""
+	if args[0] != "none" {
+		var found bool
+		language, found = resolveName(cfg, nameindex.Language, args[0])
+		if !found {
+			return nil
+		}
+	}
+
+	cfg.settings.Language = language
+	if err := settings.Save(cfg.settingsPath, cfg.settings); err != nil {
+		return fmt.Errorf("unable to save settings: %w", err)
+	}
+
+	if language == "" {
+		fmt.Println("Names will be shown as they are in the API.")
+	} else {
+		fmt.Printf("Names will be shown in '%s' where available.\n", language)
+	}
+
+	return nil
+}
+
+func commandLoad(cfg *config, args ...string) error {
+	args, flags := parseArgs(args, "repair")
+	_, repair := flags["repair"]
+
+	// the mode can be given after the slot, or on its own
+	var slotArgs []string
+	var mode savestate.LoadMode
+	for _, arg := range args {
+		switch savestate.LoadMode(arg) {
+		case savestate.Replace, savestate.Merge:
+			mode = savestate.LoadMode(arg)
+		default:
+			slotArgs = append(slotArgs, arg)
+		}
+	}
+
+	slot, path, err := chooseSlot(cfg, slotArgs)
+	if err != nil {
+		return err
+	}
+
+	if mode == "" {
+		mode = savestate.Replace
+
+		// only ask when there is something in the pokedex to lose,
+		// such as items bought or areas explored as well as pokemon caught
+		if cfg.pokedex.IsDirty() {
+			fmt.Print("Your Pokedex has unsaved changes. Replace them with the save, or merge? (replace/merge/cancel) ")
+
+			answer, _ := readLine(cfg)
+			switch savestate.LoadMode(strings.ToLower(strings.TrimSpace(answer))) {
+			case savestate.Replace:
+				mode = savestate.Replace
+			case savestate.Merge:
+				mode = savestate.Merge
+			default:
+				fmt.Println("Nothing was loaded.")
+				return nil
+			}
+		}
+	}
+
+	result, err := savestate.LoadPokedex(path, cfg.pokedex, savestate.LoadOptions{
+		Mode:         mode,
+		Repair:       repair,
+		KnownPokemon: func(name string) bool { return knownName(cfg, nameindex.Pokemon, name) },
+	})
+
+	var integrityErr *savestate.IntegrityError
+	if errors.As(err, &integrityErr) {
+		printIntegrityReport(integrityErr.Report)
+		fmt.Printf("Nothing was loaded. Use 'load %s --repair' to load the entries that are fine.\n", slot)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("unable to load save: %w", err)
+	}
+
+	cfg.slot = slot
+	cfg.slotInUse = true
+
+	if !result.Report.OK() {
+		printIntegrityReport(result.Report)
+		fmt.Println("The entries that are fine were loaded, save to replace the damaged save.")
+	}
+
+	conflicts := result.Conflicts
+	if len(conflicts) > 0 {
+		fmt.Printf("%d Pokemon differ between your Pokedex and the save, your Pokedex was kept for:\n", len(conflicts))
+		for _, conflict := range conflicts {
+			fmt.Printf("  -%s\n", caughtLabel(conflict.InPokedex))
+		}
+		fmt.Printf("Use 'diff %s' to see the differences, or 'load %s replace' to use the save.\n", slot, slot)
+	}
+
+	// the larger amount of each item and of money was kept
+	var fromSave []string
+	for _, item := range result.ItemsFromSave {
+		fromSave = append(fromSave, fmt.Sprintf("%d %s", item.Quantity, pokedex.ItemName(item.Name)))
+	}
+	if result.MoneyFromSave {
+		fromSave = append(fromSave, fmt.Sprintf("$%d", cfg.pokedex.Money()))
+	}
+	if len(fromSave) > 0 {
+		fmt.Printf("The save had more of these, so they were taken from it: %s.\n", strings.Join(fromSave, ", "))
+	}
+
+	return nil
+}
+
+func commandMap(cfg *config, args ...string) error {
+	// checking URL
+	var URL string
+	if cfg.mapNURL == "null" {
+		URL = pokeapi.LocationAreaListURL
+	} else {
+		URL = cfg.mapNURL
+	}
+
+	// check the cache
+	body, err := requestThroughCache(URL, cfg)
+	if err != nil {
+		return fmt.Errorf("unable to request data: %w", err)
+	}
+
+	// add to cache
+	cfg.cache.Add(URL, body)
+
+	locationList, err := pokeapi.UnmarshalLocationList(body)
+	if err != nil {
+		return fmt.Errorf("unable to unmarshal json request: %w", err)
+	}
+
+	var slugs []string
+	for _, loc := range locationList.Results {
+		slugs = append(slugs, loc.Name)
+	}
+	for _, name := range localizeAll(cfg, slugs, localizedAreaName) {
+		fmt.Println(name)
+	}
+
+	if locationList.Next != nil {
+		cfg.mapNURL = *locationList.Next
+	}
+	if locationList.Previous != nil {
+		cfg.mapPURL = *locationList.Previous
+	}
+
+	return nil
+}
+
+func commandMapB(cfg *config, args ...string) error {
+	// checking for URL
+	var URL string
+	if cfg.mapPURL == "null" {
+		URL = pokeapi.LocationAreaListURL
+	} else {
+		URL = cfg.mapPURL
+	}
+
+	// check the cache
+	body, err := requestThroughCache(URL, cfg)
+	if err != nil {
+		return fmt.Errorf("unable to request body: %w", err)
+	}
+
+	// add to cache
+	cfg.cache.Add(URL, body)
+
+	locationList, err := pokeapi.UnmarshalLocationList(body)
+	if err != nil {
+		return fmt.Errorf("unable to unmarshal json request: %w", err)
+	}
+
+	var slugs []string
+	for _, loc := range locationList.Results {
+		slugs = append(slugs, loc.Name)
+	}
+	for _, name := range localizeAll(cfg, slugs, localizedAreaName) {
+		fmt.Println(name)
+	}
+
+	if locationList.Next != nil {
+		cfg.mapNURL = *locationList.Next
+	}
+	if locationList.Previous != nil {
+		cfg.mapPURL = *locationList.Previous
+	}
+
+	return nil
+}
+
+func commandNickname(cfg *config, args ...string) error {
+	if len(args) == 0 {
+		fmt.Println("Please provide the ID of a caught Pokemon and a nickname, such as 'nickname 0a1b2c Sparky'.")
+		fmt.Println("Use 'inspect <pokemon>' to see the IDs of the ones you caught.")
+		return nil
+	}
+
+	ID := strings.TrimPrefix(strings.ToLower(args[0]), "#")
+	nickname := strings.Join(args[1:], " ")
+
+	individual, found := cfg.pokedex.Find(ID)
+	if !found || !cfg.pokedex.SetNickname(ID, nickname) {
+		fmt.Printf("You have not caught a Pokemon with the ID '%s'.\n", ID)
+		return nil
+	}
+
+	if nickname == "" {
+		fmt.Printf("#%s is now called %s again.\n", ID, individual.Species)
+		return nil
+	}
+
+	fmt.Printf("#%s %s is now called %s.\n", ID, individual.Species, nickname)
+	return nil
+}
+
+func commandParty(cfg *config, args ...string) error {
+	individuals := cfg.pokedex.Party()
+	if len(individuals) == 0 {
+		fmt.Println("Your party is empty. Catch a Pokemon to add it.")
+		return nil
+	}
+
+	fmt.Printf("Your party (%d/%d):\n", len(individuals), pokedex.PartySize)
+	return printStorage(individuals)
+}
+
+func commandPokedex(cfg *config, args ...string) error {
+	_, flags := parseArgs(args)
+
+	query := pokedex.Query{
+		Sort: pokedex.SortKey(flags["sort"]),
+	}
+
+	if types, ok := flags["type"]; ok {
+		query.Types = strings.Split(types, ",")
+	}
+
+	if gen, ok := flags["gen"]; ok {
+		name, found := resolveName(cfg, nameindex.Generation, gen)
+		if !found {
+			return nil
+		}
+
+		generation, err := requestResource(cfg, nameindex.Generation, name, pokeapi.UnmarshalGeneration)
+		if err != nil {
+			return fmt.Errorf("unable to request generation '%s': %w", name, err)
+		}
+
+		query.Species = make(map[string]bool)
+		for _, species := range generation.PokemonSpecies {
+			query.Species[species.Name] = true
+		}
+	}
+
+	// such as attack=100,speed=90
+	if minStats, ok := flags["min-stat"]; ok {
+		query.MinStats = make(map[string]int)
+		for _, pair := range strings.Split(minStats, ",") {
+			stat, value, found := strings.Cut(pair, "=")
+			minimum, err := strconv.Atoi(value)
+			if !found || err != nil {
+				fmt.Printf("'%s' is not a stat and a value, such as attack=100.\n", pair)
+				return nil
+			}
+			query.MinStats[stat] = minimum
+		}
+	}
+
+	if limit, ok := flags["limit"]; ok {
+		number, err := strconv.Atoi(limit)
+		if err != nil {
+			fmt.Printf("'%s' is not a number.\n", limit)
+			return nil
+		}
+		query.Limit = number
+	}
+
+	results, err := cfg.pokedex.Query(query)
+	if err != nil {
+		fmt.Println("Unable to search your Pokedex:", err)
+		return nil
+	}
+
+	if len(results) == 0 {
+		if len(flags) > 0 {
+			fmt.Println("None of your Pokemon match.")
+			return nil
+		}
+		fmt.Println("You have not caught any Pokemon yet!")
+		return nil
+	}
+
+	fmt.Println("Your Pokedex:")
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "NO.\tNAME\tTYPES\tBST\tCAUGHT")
+	for _, result := range results {
+		var types []string
+		for _, pType := range result.Pokemon.TypeList {
+			types = append(types, pType.PType.Name)
+		}
+
+		caught := fmt.Sprintf("%d", len(result.Caught))
+		if result.Imported() {
+			caught = "imported"
+		}
+
+		fmt.Fprintf(writer, "%d\t%s\t%s\t%d\t%s\n",
+			result.Pokemon.ID, result.Pokemon.Name, strings.Join(types, "/"), result.BaseStatTotal, caught)
+	}
+
+	return writer.Flush()
+}
+
+func commandProgress(cfg *config, args ...string) error {
+	seen := cfg.pokedex.Seen()
+	caught, _ := cfg.pokedex.GetAll()
+	fmt.Printf("You have seen %d Pokemon and caught %d.\n", len(seen), len(caught))
+
+	species, err := cfg.names.Entries(nameindex.PokemonSpecies)
+	if err != nil {
+		return fmt.Errorf("unable to list every species: %w", err)
+	}
+	speciesNames := make([]string, 0, len(species))
+	for _, entry := range species {
+		speciesNames = append(speciesNames, entry.Name)
+	}
+	forms := seenForms(cfg, speciesNames)
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	if len(args) > 0 {
+		fmt.Fprintln(writer, "POKEDEX\tSEEN\tCAUGHT\tTOTAL\tCOMPLETE")
+
+		// every regional pokedex, or only the one named
+		var names []string
+		if args[0] == "regions" {
+			entries, err := cfg.names.Entries(nameindex.Pokedex)
+			if err != nil {
+				return fmt.Errorf("unable to list the regional pokedexes: %w", err)
+			}
+			for _, entry := range entries {
+				names = append(names, entry.Name)
+			}
+		} else {
+			name, found := resolveName(cfg, nameindex.Pokedex, strings.Join(args, " "))
+			if !found {
+				return nil
+			}
+			names = append(names, name)
+		}
+
+		for _, name := range names {
+			progress, err := regionalProgress(cfg, name, forms)
+			if err != nil {
+				return fmt.Errorf("unable to request pokedex '%s': %w", name, err)
+			}
+
+			// some pokedexes are only used outside the main games and are empty
+			if progress.Total > 0 {
+				writeProgress(writer, progress)
+			}
+		}
+
+		return writer.Flush()
+	}
+
+	generations, err := cfg.names.Entries(nameindex.Generation)
+	if err != nil {
+		return fmt.Errorf("unable to list the generations: %w", err)
+	}
+
+	fmt.Fprintln(writer, "GENERATION\tSEEN\tCAUGHT\tTOTAL\tCOMPLETE")
+	writeProgress(writer, cfg.pokedex.Progress("overall", speciesNames, forms))
+
+	for _, entry := range generations {
+		generation, err := requestResource(cfg, nameindex.Generation, entry.Name, pokeapi.UnmarshalGeneration)
+		if err != nil {
+			return fmt.Errorf("unable to request generation '%s': %w", entry.Name, err)
+		}
+
+		speciesNames := make([]string, 0, len(generation.PokemonSpecies))
+		for _, species := range generation.PokemonSpecies {
+			speciesNames = append(speciesNames, species.Name)
+		}
+
+		writeProgress(writer, cfg.pokedex.Progress(localizedName(cfg, generation.Names, generation.Name), speciesNames, forms))
+	}
+
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Println("Use 'progress <pokedex>', such as 'progress kanto', for a regional Pokedex, or 'progress regions' for all of them.")
+	return nil
+}
+
+func commandRefresh(cfg *config, args ...string) error {
+	species := cfg.pokedex.Species()
+	if len(species) == 0 {
+		fmt.Println("You have not caught any Pokemon yet!")
+		return nil
+	}
+
+	for _, pokemonStruct := range species {
+		// the cached data is what is being replaced
+		cfg.cache.Remove(resourceURL(nameindex.Pokemon, pokemonStruct.Name))
+		pokemon, err := requestResource(cfg, nameindex.Pokemon, pokemonStruct.Name, pokeapi.UnmarshalPokemonInfo)
+		if err != nil {
+			return fmt.Errorf("unable to refresh '%s': %w", pokemonStruct.Name, err)
+		}
+
+		// only the species data changes, each caught pokemon is kept
+		cfg.pokedex.UpdateSpecies(pokemon)
+	}
+
+	fmt.Printf("Refreshed %d Pokemon from the PokeAPI.\n", len(species))
+	return nil
+}
+
+func commandRestore(cfg *config, args ...string) error {
+	// a slot can be given before the backup number
+	var slotArgs []string
+	if len(args) > 0 {
+		if _, err := strconv.Atoi(args[0]); err != nil {
+			slotArgs, args = args[:1], args[1:]
+		}
+	}
+
+	slot, path, err := chooseSlot(cfg, slotArgs)
+	if err != nil {
+		return err
+	}
+
+	backups, err := savestate.ListBackups(path)
+	if err != nil {
+		return fmt.Errorf("unable to list backups: %w", err)
+	}
+
+	if len(backups) == 0 {
+		fmt.Printf("There are no backups of slot '%s' to restore.\n", slot)
+		return nil
+	}
+
+	if len(args) == 0 {
+		fmt.Printf("Backups of slot '%s', newest first:\n", slot)
+		for i, backup := range backups {
+			fmt.Printf("  %d: replaced at %s\n", i+1, backup.Replaced.Local().Format(time.DateTime))
+		}
+		fmt.Printf("Use 'restore %s <number>' to restore one of them.\n", slot)
+		return nil
+	}
+
+	number, err := strconv.Atoi(args[0])
+	if err != nil || number < 1 || number > len(backups) {
+		fmt.Printf("Please provide a backup number from 1 to %d.\n", len(backups))
+		return nil
+	}
+
+	backup := backups[number-1]
+	if err := savestate.RestoreBackup(path, backup); err != nil {
+		return fmt.Errorf("unable to restore backup: %w", err)
+	}
+
+	fmt.Printf("Restored the save replaced at %s.\n", backup.Replaced.Local().Format(time.DateTime))
+	fmt.Printf("Use 'load %s' to load it.\n", slot)
+	return nil
+}
+
+func commandSave(cfg *config, args ...string) error {
+	slot, path, err := chooseSlot(cfg, args)
+	if err != nil {
+		return err
+	}
+
+	err = savestate.SavePokedex(path, cfg.pokedex)
+	if err != nil {
+		return fmt.Errorf("unable to save pokedex: %w", err)
+	}
+
+	cfg.slot = slot
+	cfg.slotInUse = true
+	return nil
+}
+
+func commandSell(cfg *config, args ...string) error {
+	if len(args) == 0 {
+		fmt.Println("Please provide an item to sell, such as 'sell potion 2'.")
+		return nil
+	}
+
+	name, quantity, err := parseItemArgs(args)
+	if err != nil {
+		fmt.Println(err)
+		return nil
+	}
+
+	if cfg.pokedex.ItemCount(name) == 0 {
+		fmt.Printf("You have no %s. Use 'bag' to see your items.\n", pokedex.ItemName(name))
+		return nil
+	}
+
+	item, err := requestResource(cfg, nameindex.Item, name, pokeapi.UnmarshalItemInfo)
+	if err != nil {
+		return fmt.Errorf("unable to request item '%s': %w", name, err)
+	}
+
+	earned, err := cfg.pokedex.Sell(name, quantity, item.Cost)
+	if err != nil {
+		fmt.Println("Unable to sell:", err)
+		return nil
+	}
+
+	fmt.Printf("Sold %s x%d for $%d. You have $%d.\n",
+		pokedex.ItemName(name), quantity, earned, cfg.pokedex.Money())
+	return nil
+}
+
+func commandShop(cfg *config, args ...string) error {
+	fmt.Println("Welcome to the PokeMart!")
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "ITEM\tPRICE\tIN BAG")
+	for _, name := range pokedex.ShopItems {
+		item, err := requestResource(cfg, nameindex.Item, name, pokeapi.UnmarshalItemInfo)
+		if err != nil {
+			return fmt.Errorf("unable to request item '%s': %w", name, err)
+		}
+
+		// items without a price are not sold
+		if item.Cost <= 0 {
+			continue
+		}
+
+		fmt.Fprintf(writer, "%s\t$%d\t%d\n", localizedName(cfg, item.Names, pokedex.ItemName(name)), item.Cost, cfg.pokedex.ItemCount(name))
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("You have $%d. Use 'buy <item> [quantity]' to buy.\n", cfg.pokedex.Money())
+	return nil
+}
+
+func commandSprites(cfg *config, args ...string) error {
+	if len(args) == 0 {
+		fmt.Printf("Sprites are drawn using '%s'.\n", cfg.settings.SpriteMode)
+		fmt.Println("Use 'sprites ansi', 'sprites ascii' or 'sprites off' to change it.")
+		return nil
+	}
+
+	mode := sprite.Mode(args[0])
+	if mode != sprite.ANSI && mode != sprite.ASCII && mode != sprite.Off {
+		fmt.Println("Please choose either 'ansi', 'ascii' or 'off' for sprites.")
+		return nil
+	}
+
+	cfg.settings.SpriteMode = string(mode)
+	if err := settings.Save(cfg.settingsPath, cfg.settings); err != nil {
+		return fmt.Errorf("unable to save settings: %w", err)
+	}
+
+	fmt.Printf("Sprites will be drawn using '%s'.\n", mode)
+	return nil
+}
+
+func commandSlots(cfg *config, args ...string) error {
+	slots, err := savestate.ListSlots(cfg.slotDir)
+	if err != nil {
+		return fmt.Errorf("unable to list slots: %w", err)
+	}
+
+	if len(slots) == 0 {
+		fmt.Println("There are no saved slots yet.")
+		return nil
+	}
+
+	// aligns the columns of the table
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "SLOT\tSAVED\tPOKEMON")
+	for _, slot := range slots {
+		name := slot.Name
+		if name == cfg.slot {
+			name += " (current)"
+		}
+
+		if slot.Err != nil {
+			fmt.Fprintf(writer, "%s\tunreadable: %s\t\n", name, slot.Err)
+			continue
+		}
+
+		if slot.Damaged {
+			name += " (damaged)"
+		}
+
+		fmt.Fprintf(writer, "%s\t%s\t%d\n", name, slot.SaveTime.Local().Format(time.DateTime), slot.Count)
+	}
+
+	return writer.Flush()
+}
+
+func commandSwap(cfg *config, args ...string) error {
+	if len(args) < 2 {
+		fmt.Println("Please provide the IDs of two caught Pokemon, such as 'swap 0a1b2c 3d4e5f'.")
+		return nil
+	}
+
+	firstID, secondID := strings.TrimPrefix(args[0], "#"), strings.TrimPrefix(args[1], "#")
+	if err := cfg.pokedex.Swap(firstID, secondID); err != nil {
+		fmt.Println("Unable to swap:", err)
+		return nil
+	}
+
+	first, _ := cfg.pokedex.Find(firstID)
+	second, _ := cfg.pokedex.Find(secondID)
+	fmt.Printf("Swapped %s and %s.\n", caughtLabel(first), caughtLabel(second))
+	return nil
+}
+
+func commandTeam(cfg *config, args ...string) error {
+	if len(args) == 0 {
+		team := cfg.pokedex.Team()
+		if len(team) == 0 {
+			fmt.Println("You do not have a team yet, use 'team import <file>' to import a Showdown paste.")
+			return nil
+		}
+
+		return showdown.Format(os.Stdout, team)
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "import":
+		path := strings.Join(args[1:], " ")
+		if path == "" {
+			fmt.Println("Please provide a file with a Showdown paste, such as 'team import team.txt'.")
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("unable to open team file: %w", err)
+		}
+		defer file.Close()
+
+		team, err := showdown.Parse(file)
+		if err != nil {
+			fmt.Println("Unable to read the team:", err)
+			return nil
+		}
+
+		if len(team) == 0 {
+			fmt.Println("There are no Pokemon in the team file.")
+			return nil
+		}
+		if len(team) > showdown.MaxTeamSize {
+			fmt.Printf("A team can have at most %d Pokemon, the file has %d.\n", showdown.MaxTeamSize, len(team))
+			return nil
+		}
+
+		// the team is only imported when every member is valid
+		valid := true
+		for i := range team {
+			problems := validateSet(cfg, &team[i])
+			if len(problems) == 0 {
+				continue
+			}
+
+			valid = false
+			fmt.Printf("%s:\n", showdown.SpeciesName(team[i]))
+			for _, problem := range problems {
+				fmt.Printf("  - %s\n", problem)
+			}
+		}
+		if !valid {
+			fmt.Println("The team was not imported.")
+			return nil
+		}
+
+		cfg.pokedex.SetTeam(team)
+		fmt.Printf("Imported a team of %d Pokemon from %s.\n", len(team), path)
+		return nil
+
+	case "export":
+		if len(args) < 2 || strings.ToLower(args[1]) != "showdown" {
+			fmt.Println("Please provide the format to export, such as 'team export showdown team.txt'.")
+			return nil
+		}
+
+		team := cfg.pokedex.Team()
+		if len(team) == 0 {
+			fmt.Println("You do not have a team to export.")
+			return nil
+		}
+
+		// without a file the team is written to the terminal, ready to paste
+		path := strings.Join(args[2:], " ")
+		if path == "" || path == "-" {
+			return showdown.Format(os.Stdout, team)
+		}
+
+		file, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("unable to create team file: %w", err)
+		}
+		defer file.Close()
+
+		if err := showdown.Format(file, team); err != nil {
+			return fmt.Errorf("unable to export team: %w", err)
+		}
+
+		fmt.Printf("Exported a team of %d Pokemon to %s.\n", len(team), path)
+		return nil
+
+	case "clear":
+		cfg.pokedex.SetTeam(nil)
+		fmt.Println("Your team was cleared.")
+		return nil
+	}
+
+	fmt.Println("Please use 'team', 'team import <file>', 'team export showdown [file]' or 'team clear'.")
+	return nil
+}
+
+func commandTravel(cfg *config, args ...string) error {
+	name := strings.Join(args, " ")
+	if name == "" {
+		location := cfg.pokedex.Location()
+		if location == "" {
+			fmt.Println("Please provide the name of an area to travel to, use 'map' to find one.")
+			return nil
+		}
+		fmt.Printf("You are in %s. Please provide the name of an area to travel to.\n", localizedAreaName(cfg, location))
+		return nil
+	}
+
+	name, found := resolveName(cfg, nameindex.LocationArea, name)
+	if !found {
+		return nil
+	}
+
+	if name == cfg.pokedex.Location() {
+		fmt.Printf("You are already in %s.\n", localizedAreaName(cfg, name))
+		return nil
+	}
+
+	// the area is requested first, so that only real areas can be traveled to
+	locationInfo, err := requestResource(cfg, nameindex.LocationArea, name, pokeapi.UnmarshalLocationInfo)
+	if err != nil {
+		return fmt.Errorf("unable to request area '%s': %w", name, err)
+	}
+
+	cfg.pokedex.SetLocation(locationInfo.Name)
+	fmt.Printf("You traveled to %s.\n", localizedAreaName(cfg, locationInfo.Name))
+	if len(locationInfo.PokemonList) == 0 {
+		fmt.Println("No wild Pokemon live here.")
+	} else {
+		fmt.Printf("%d kinds of Pokemon live here, use 'explore' to see them.\n", len(locationInfo.PokemonList))
+	}
+	if !slices.Contains(cfg.pokedex.Explored(), locationInfo.Name) {
+		fmt.Println("This area has not been explored yet, there may be something to find.")
+	}
+
+	return nil
+}
+
+func commandUnits(cfg *config, args ...string) error {
+	if len(args) == 0 {
+		fmt.Printf("Heights and weights are shown in %s units.\n", cfg.settings.Units)
+		fmt.Println("Use 'units metric' or 'units imperial' to change them.")
+		return nil
+	}
+
+	units := display.Units(args[0])
+	if units != display.Metric && units != display.Imperial {
+		fmt.Println("Please choose either 'metric' or 'imperial' units.")
+		return nil
+	}
+
+	cfg.settings.Units = string(units)
+	if err := settings.Save(cfg.settingsPath, cfg.settings); err != nil {
+		return fmt.Errorf("unable to save settings: %w", err)
+	}
+
+	fmt.Printf("Heights and weights will be shown in %s units.\n", units)
+	return nil
+}
+
+func commandWhere(cfg *config, args ...string) error {
+	name := strings.Join(args, " ")
+	if name == "" {
+		fmt.Println("Please provide the name of a Pokemon to look for.")
+		return nil
+	}
+
+	name, found := resolveName(cfg, nameindex.Pokemon, name)
+	if !found {
+		return nil
+	}
+
+	URL := pokeapi.PokemonInfoURL + name + pokeapi.PokemonEncountersPath
+
+	data, err := requestThroughCache(URL, cfg)
+	if err != nil {
+		return fmt.Errorf("unable to request through cache: %w", err)
+	}
+
+	cfg.cache.Add(URL, data)
+
+	encounters, err := pokeapi.UnmarshalPokemonEncounters(data)
+	if err != nil {
+		return fmt.Errorf("unable to unmarshal pokemon encounters: %w", err)
+	}
+
+	summaries := pokeapi.SummarizeEncounters(encounters)
+	if len(summaries) == 0 {
+		fmt.Printf("%s cannot be found in the wild.\n", name)
+		return nil
+	}
+
+	// aligns the columns of the table
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "AREA\tVERSION\tMETHOD\tLEVELS\tCHANCE")
+	for _, summary := range summaries {
+		levels := fmt.Sprintf("%d-%d", summary.MinLevel, summary.MaxLevel)
+		if summary.MinLevel == summary.MaxLevel {
+			levels = fmt.Sprintf("%d", summary.MinLevel)
+		}
+
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%d%%\n",
+			summary.LocationArea, summary.Version, summary.Method, levels, summary.Chance)
+	}
+
+	return writer.Flush()
+}
+
+func commandWithdraw(cfg *config, args ...string) error {
+	if len(args) == 0 {
+		fmt.Println("Please provide the ID of a Pokemon in the PC, such as 'withdraw 0a1b2c'.")
+		return nil
+	}
+
+	ID := strings.TrimPrefix(args[0], "#")
+	if err := cfg.pokedex.Withdraw(ID); err != nil {
+		fmt.Println("Unable to withdraw:", err)
+		return nil
+	}
+
+	individual, _ := cfg.pokedex.Find(ID)
+	fmt.Printf("%s joined your party.\n", caughtLabel(individual))
+	return nil
+}
+
+// =============
+// Main Function
+// =============
+func main() {
+
+	const interval = (10 * time.Minute)
+	// where saves were kept before there were slots
+	const legacySaveFilePath = "./save.json"
+
+	profile := flag.String("profile", "default", "the trainer profile, each profile keeps its own save slots")
+	seed := flag.Int64("seed", 0, "seeds the randomness of catches, so that a session can be repeated")
+	flag.Parse()
+
+	source := pokedex.NewRandomSource()
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "seed" {
+			source = pokedex.NewSource(*seed, pokedex.SystemClock{})
+		}
+	})
+
+	slotDir, err := paths.SlotDir(*profile)
+	if err != nil {
+		fmt.Println("Unable to find the save folder:", err)
+		os.Exit(1)
+	}
+
+	configDir, err := paths.ConfigDir()
+	if err != nil {
+		fmt.Println("Unable to find the settings folder:", err)
+		os.Exit(1)
+	}
+
+	cacheDir, err := paths.CacheDir()
+	if err != nil {
+		fmt.Println("Unable to find the cache folder:", err)
+		os.Exit(1)
+	}
+
+	for _, dir := range []string{slotDir, configDir, cacheDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			fmt.Println("Unable to create folder:", err)
+			os.Exit(1)
+		}
+	}
+
+	settingsFilePath := filepath.Join(configDir, "settings.json")
+	keyFilePath := filepath.Join(configDir, "save.key")
+	nameIndexPath := filepath.Join(cacheDir, "nameindex.json")
+
+	loadedSettings, err := settings.Load(settingsFilePath)
+	if err != nil {
+		fmt.Println("Unable to load settings, using the defaults:", err)
+	}
+
+	key, err := savestate.LoadOrCreateKey(keyFilePath)
+	if err != nil {
+		fmt.Println("Unable to load the key for checking saves:", err)
+	}
+	savestate.SetIntegrityKey(key)
+
+	// local variables struct
+	cfg := &config{
+		cache:        pokecache.NewCache(interval),
+		mapNURL:      "null",
+		mapPURL:      "null",
+		names:        nameindex.NewIndex(nameIndexPath),
+		pokedex:      pokedex.NewPokedex(),
+		slot:         savestate.DefaultSlot,
+		slotDir:      slotDir,
+		settings:     loadedSettings,
+		settingsPath: settingsFilePath,
+		source:       source,
+	}
+	cfg.pokedex.SetSource(source)
+
+	// the save from before slots becomes the default slot of the default profile
+	if *profile == "default" {
+		moved, err := savestate.AdoptLegacySave(legacySaveFilePath, slotDir)
+		if err != nil {
+			fmt.Println("Unable to move the old save into a slot:", err)
+		} else if moved {
+			fmt.Printf("Moved %s into the '%s' save slot.\n", legacySaveFilePath, savestate.DefaultSlot)
+		}
+	}
+
+	cfg.input = readLines(os.Stdin)
+
+	// saves before quitting when interrupted or terminated,
+	// once the running command has returned
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	cfg.signals = signals
+
+	if err := resetAutosaveTimer(cfg); err != nil {
+		fmt.Println("Unable to start the autosave timer:", err)
+	}
+
+	for {
+		fmt.Print("Pokedex > ") // prompt
+
+		var text string
+		select {
+		case <-autosaveTimer(cfg):
+			fmt.Println()
+			autosave(cfg)
+			continue
+
+		case <-cfg.signals:
+			fmt.Println()
+			quitOnSignal(cfg)
+
+		case line, ok := <-cfg.input:
+			if !ok {
+				// input has ended, such as with ctrl+d
+				fmt.Println()
+				if err := commandExit(cfg); err != nil {
+					fmt.Println("Error in commands:", err)
+					os.Exit(1)
+				}
+			}
+			text = line
+		}
+
+		args := cleanInput(text)
+		if len(args) == 0 {
+			continue // only whitespace provided
+		}
+		command := args[0]
+
+		validCommand, exists := validCommands[command]
+		if !exists {
+			fmt.Println("Unknown command")
+			continue
+		}
+
+		if validCommand.keepCase {
+			args = strings.Fields(text)
+		}
+
+		// pass in local variables struct, and any arguments
+		if err := validCommand.callback(cfg, args[1:]...); err != nil {
+			fmt.Println("Error in commands:", err)
+		}
+
+		// aliases of the names shown by the command are written once
+		if err := cfg.names.Flush(); err != nil {
+			fmt.Println("Unable to save the name index:", err)
+		}
+
+		if cfg.interrupted {
+			quitOnSignal(cfg)
 		}
 
 		// adds a line between last command and the next prompt
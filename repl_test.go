@@ -2,7 +2,16 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	nameindex "github.com/nicholasss/pokedexcli/internal/nameindex"
+	pokeapi "github.com/nicholasss/pokedexcli/internal/pokeapi"
+	pokecache "github.com/nicholasss/pokedexcli/internal/pokecache"
+	showdown "github.com/nicholasss/pokedexcli/internal/showdown"
 )
 
 func TestCleanInput(t *testing.T) {
@@ -60,3 +69,122 @@ func TestCleanInput(t *testing.T) {
 	}
 
 }
+
+func TestParseArgs(t *testing.T) {
+	cases := []struct {
+		input              []string
+		switches           []string
+		expectedPositional []string
+		expectedFlags      map[string]string
+	}{
+		{
+			input:              []string{"pikachu"},
+			expectedPositional: []string{"pikachu"},
+			expectedFlags:      map[string]string{},
+		},
+		{
+			input:              []string{"pikachu", "--shiny", "--back"},
+			switches:           []string{"shiny", "back"},
+			expectedPositional: []string{"pikachu"},
+			expectedFlags:      map[string]string{"shiny": "", "back": ""},
+		},
+		{
+			input:              []string{"--ball", "ultra", "mr.", "mime"},
+			expectedPositional: []string{"mr.", "mime"},
+			expectedFlags:      map[string]string{"ball": "ultra"},
+		},
+		{
+			input:              []string{"pikachu", "--ball"},
+			expectedPositional: []string{"pikachu"},
+			expectedFlags:      map[string]string{"ball": ""},
+		},
+	}
+
+	for _, c := range cases {
+		positional, flags := parseArgs(c.input, c.switches...)
+
+		if len(positional) != len(c.expectedPositional) {
+			t.Errorf("expected positional %v, got %v", c.expectedPositional, positional)
+			return
+		}
+		for i := range positional {
+			if positional[i] != c.expectedPositional[i] {
+				t.Errorf("expected positional %v, got %v", c.expectedPositional, positional)
+				return
+			}
+		}
+
+		if len(flags) != len(c.expectedFlags) {
+			t.Errorf("expected flags %v, got %v", c.expectedFlags, flags)
+			return
+		}
+		for name, value := range c.expectedFlags {
+			if actual, ok := flags[name]; !ok || actual != value {
+				t.Errorf("expected flags %v, got %v", c.expectedFlags, flags)
+				return
+			}
+		}
+	}
+}
+
+func TestTeamRoundTrip(t *testing.T) {
+	// the names and data the team is checked against, so that nothing is requested
+	indexPath := filepath.Join(t.TempDir(), "nameindex.json")
+	index := `{"entries": {
+		"pokemon": [{"name": "landorus-incarnate", "id": 645}, {"name": "landorus-therian", "id": 10021}],
+		"pokemon-species": [{"name": "landorus", "id": 645}],
+		"move": [{"name": "earthquake", "id": 89}]
+	}}`
+	if err := os.WriteFile(indexPath, []byte(index), 0644); err != nil {
+		t.Errorf("unable to write name index: %s", err)
+		return
+	}
+
+	cfg := &config{
+		cache: pokecache.NewCache(time.Minute),
+		names: nameindex.NewIndex(indexPath),
+	}
+	responses := map[string]string{
+		pokeapi.PokemonInfoURL + "landorus-therian/": `{"name": "landorus-therian", "species": {"name": "landorus"}}`,
+		pokeapi.PokemonSpeciesURL + "landorus/": `{"name": "landorus", "varieties": [
+			{"is_default": true, "pokemon": {"name": "landorus-incarnate"}},
+			{"is_default": false, "pokemon": {"name": "landorus-therian"}}
+		]}`,
+		pokeapi.MoveURL + "earthquake/": `{"name": "earthquake", "learned_by_pokemon": [
+			{"name": "landorus-incarnate"}, {"name": "landorus-therian"}
+		]}`,
+	}
+	for URL, data := range responses {
+		cfg.cache.Add(URL, []byte(data))
+	}
+
+	cases := []string{
+		"Landorus-Therian\n- Earthquake\n",
+		"Landorus\n- Earthquake\n",
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			team, err := showdown.Parse(strings.NewReader(c))
+			if err != nil || len(team) != 1 {
+				t.Errorf("unable to parse team: %v", err)
+				return
+			}
+
+			if problems := validateSet(cfg, &team[0]); len(problems) != 0 {
+				t.Errorf("expected a valid team, got %v", problems)
+				return
+			}
+
+			// the team is exported as it was imported
+			var written strings.Builder
+			if err := showdown.Format(&written, team); err != nil {
+				t.Errorf("unable to export team: %s", err)
+				return
+			}
+			if written.String() != c {
+				t.Errorf("expected '%s', got '%s'", c, written.String())
+			}
+		})
+	}
+}